@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+)
+
+// configCmd handles `config print <config_file>`: the effective config after the environment overrides,
+// and `config schema`: the JSON Schema of the config file
+func configCmd(args []string) {
+	if len(args) == 1 && args[0] == "schema" {
+		os.Stdout.Write(config.Schema)
+		return
+	}
+	if len(args) != 2 || args[0] != "print" {
+		log.Fatalf("Usage: %s config print <config_file> | config schema\n", os.Args[0])
+	}
+
+	conf, err := config.LoadConfig(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := json.MarshalIndent(conf, "", "    ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+}
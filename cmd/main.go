@@ -2,18 +2,31 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"reflect"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata" // the venue time zones are available in minimal images
 
+	"github.com/GitProger/go-telecom-2025/internal/api"
+	"github.com/GitProger/go-telecom-2025/internal/clock"
 	"github.com/GitProger/go-telecom-2025/internal/config"
 	"github.com/GitProger/go-telecom-2025/internal/model"
 	"github.com/GitProger/go-telecom-2025/internal/monitor"
 	"github.com/GitProger/go-telecom-2025/internal/provider"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+	"github.com/GitProger/go-telecom-2025/internal/tui"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
 )
 
 func printNonNil(s any) {
@@ -46,8 +59,14 @@ func mainSimple() { // file only
 		if err != nil {
 			log.Fatal(err)
 		}
+		before, after := splitAt(out, event.Time)
+		for _, e := range before {
+			printNonNil(e)
+		}
 		fmt.Println(event)
-		printNonNil(out)
+		for _, e := range after {
+			printNonNil(e)
+		}
 	}
 
 	for _, e := range m.Disqualified() {
@@ -60,32 +79,126 @@ func mainSimple() { // file only
 	}
 }
 
+// splitAt splits the outgoing events of the digested event into the ones scheduled before its time
+// and the ones it has caused
+func splitAt(out []*model.Event, t time.Time) (before, after []*model.Event) {
+	i := slices.IndexFunc(out, func(e *model.Event) bool { return e == nil || !e.Time.Before(t) })
+	if i < 0 {
+		return out, nil
+	}
+	return out[:i], out[i:]
+}
+
+var commands = map[string]func(args []string){
+	"config":    configCmd,
+	"simulate":  simulateCmd,
+	"standings": standingsCmd,
+}
+
 func main() { // interactive
 	// mainSimple()
 	// return
 
-	if len(os.Args) < 2 {
-		log.Fatalf("Usage: %s <config_file> [event_file]\n", os.Args[0])
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	var raceFiles []raceFile
+	flag.Func("race", "add the race `id=config_file`, repeat it to run several races at once, the events carry the race ID then "+
+		"or are routed by their race day with the IDs like day2 or 2025-03-02", func(s string) error {
+		id, path, ok := strings.Cut(s, "=")
+		if !ok || !model.IsRaceID(id) || path == "" {
+			return fmt.Errorf("must be id=config_file, the ID is a word starting with a letter or a date: %q", s)
+		}
+		raceFiles = append(raceFiles, raceFile{id, path})
+		return nil
+	})
+	clockMode := flag.String("clock", "event", "race clock: \"event\" follows the event times, \"wall\" follows the system time for live races")
+	sourceTZ := flag.String("source-tz", "", "IANA time zone of the event source, the venue time zone by default")
+	displayUTC := flag.Bool("utc", false, "render times in UTC instead of the venue time")
+	watch := flag.Bool("watch", false, "reload the config file when it changes during the race")
+	journalFile := flag.String("journal", "", "append the digested events to the journal file, replay it with -source-tz UTC")
+	tuiMode := flag.Bool("tui", false, "show the live scoreboard instead of the event log")
+	httpAddr := flag.String("http", "", "serve the results API on the address, e.g. :8080")
+	htmlFile := flag.String("html", "", "write the final results page to the `file`")
+	pdfFile := flag.String("pdf", "", "write the official results sheet to the PDF `file`")
+	replaySpeed := flag.Float64("replay", 0, "replay the event file at its pace, times the real pace, controlled by the keys and the HTTP API")
+	snapshotFile := flag.String("snapshot", "snapshot.json", "on Ctrl+C save the race state to the `file` to resume from, empty to not save")
+	resumeFile := flag.String("resume", "", "resume the interrupted run from the snapshot `file`, the events read before are skipped on the same input")
+	startList := flag.String("start-list", "", "route the events without race ID by the start list `file`: race ID and its competitor IDs per line")
+	flag.Parse()
+	args := flag.Args()
+
+	if len(raceFiles) == 0 {
+		if len(args) < 1 {
+			log.Fatalf("Usage: %s [-clock event|wall] [-source-tz zone] [-utc] [-watch] [-journal file] [-tui] [-http addr] [-html file] [-pdf file] [-start-list file] [-replay speed] [-snapshot file] [-resume file] <config_file> [event_file]\n"+
+				"       %s [flags] -race id=config_file [-race id=config_file ...] [event_file]\n", os.Args[0], os.Args[0])
+		}
+		raceFiles = []raceFile{{"", args[0]}} // the only race
+		args = args[1:]
+	}
+
+	reg := race.NewRegistry()
+	var conf *config.Config // of the first race, the races share the timing system
+	for _, rf := range raceFiles {
+		c, err := config.LoadConfig(rf.path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if _, err := reg.Add(rf.id, c); err != nil {
+			log.Fatal(err)
+		}
+		if conf == nil {
+			conf = c
+		}
+	}
+
+	router := race.NewRouter(reg)
+	if *startList != "" {
+		f, err := os.Open(*startList)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = router.LoadStartList(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
-	configFile := os.Args[1]
-	config, err := config.LoadConfig(configFile)
+
+	reg.SetDisplayUTC(*displayUTC)
+	zone, err := sourceZone(conf, *sourceTZ)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	m := monitor.NewEventMonitor(config)
-	digestLog := func(event *model.Event) {
-		out, err := m.DigestEvent(event)
-		if err != nil {
+	var snapshot race.Snapshot
+	if *resumeFile != "" {
+		var err error
+		if snapshot, err = race.ReadSnapshot(*resumeFile); err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println(event)
-		printNonNil(out)
+		if err := router.Restore(snapshot); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("resumed at %s from %s saved %s", reg.Format("").FormatTime(reg.LastEvent()), *resumeFile, snapshot.Saved)
+	}
+
+	var journal *provider.Journal
+	if *journalFile != "" {
+		var err error
+		if journal, err = provider.OpenJournal(*journalFile); err != nil {
+			log.Fatal(err)
+		}
+		defer journal.Close()
 	}
 
 	var source io.Reader
-	if len(os.Args) == 3 {
-		eventFile := os.Args[2]
+	if len(args) == 1 {
+		eventFile := args[0]
 		f, err := os.Open(eventFile)
 		if err != nil {
 			log.Fatal(err)
@@ -102,18 +215,167 @@ func main() { // interactive
 	ctrlC := make(chan os.Signal, 1)
 	signal.Notify(ctrlC, syscall.SIGINT, syscall.SIGTERM)
 
-	events, errs := provider.Scan(ctx, source)
+	var clk clock.Clock
+	var replay *provider.Replayer
+	switch {
+	case *replaySpeed != 0:
+		if *clockMode != "event" || source == os.Stdin {
+			log.Fatal("the replay needs the event file and the event clock")
+		}
+		var err error
+		if replay, err = provider.NewReplayer(*replaySpeed, 100*time.Millisecond); err != nil {
+			log.Fatal(err)
+		}
+		clk = replay
+	case *clockMode == "event":
+		clk = clock.NewReplay()
+	case *clockMode == "wall":
+		loc := time.Local // the race times are the system wall clock if the venue time zone is unknown
+		if conf.Location != nil {
+			loc = time.UTC
+		}
+		clk = clock.NewWall(ctx, 100*time.Millisecond, !model.IsTimeOnly(conf.Start), loc)
+	default:
+		log.Fatalf("unknown clock: %s", *clockMode)
+	}
+
+	if *httpAddr != "" {
+		handler := api.NewHandler(reg)
+		if replay != nil {
+			mux := http.NewServeMux()
+			mux.Handle("/", handler)
+			control := api.NewReplayHandler(replay, reg.Format(""))
+			mux.Handle("/replay", control)
+			mux.Handle("/replay/", control)
+			handler = mux
+		}
+		srv := &http.Server{Addr: *httpAddr, Handler: handler}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal(err)
+			}
+		}()
+		defer srv.Shutdown(context.Background())
+	}
+
+	emit := func(e *model.Event) {
+		if e != nil {
+			fmt.Println(e)
+		}
+	}
+	var board *tui.Board
+	var redraw <-chan time.Time
+	if *tuiMode {
+		board = tui.NewBoard(os.Stdout, reg)
+		log.SetOutput(board)
+		emit = func(e *model.Event) {
+			if e != nil {
+				board.Event(e)
+			}
+		}
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		redraw = ticker.C
+	}
 
+	advance := func(now time.Time) {
+		for _, e := range reg.Advance(now) {
+			emit(e)
+		}
+	}
+	digestLog := func(event *model.Event) {
+		if replay, ok := clk.(*clock.Replay); ok {
+			replay.Observe(event.Time)
+		}
+		out, err := router.DigestEvent(event)
+		before, after := splitAt(out, event.Time)
+		for _, e := range before {
+			emit(e)
+		}
+		var diag *race.Diagnostic
+		if errors.As(err, &diag) {
+			log.Print(diag)
+			for _, e := range after {
+				emit(e)
+			}
+			return
+		} else if err != nil {
+			log.Fatal(err)
+		}
+		if journal != nil {
+			if err := journal.Write(event); err != nil {
+				log.Fatal(err)
+			}
+		}
+		emit(event)
+		for _, e := range after {
+			emit(e)
+		}
+	}
+
+	var reloads chan raceReload
+	if *watch {
+		reloads = make(chan raceReload)
+		for _, rf := range raceFiles {
+			go func() {
+				for reload := range config.Watch(ctx, rf.path, time.Second) {
+					select {
+					case reloads <- raceReload{rf.id, reload}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+	}
+
+	events, errs := provider.ScanIn(ctx, source, zone)
+	consumed := snapshot.Consumed // events read from the input
+	if consumed > 0 {
+		events = provider.Skip(ctx, events, consumed)
+	}
+	if replay != nil {
+		events = replay.Replay(ctx, events)
+		log.Print(replayHelp)
+		go replayKeys(ctx, replay, reg.Format(""), os.Stdin)
+	}
+	ticks := clk.Ticks()
+
+	interrupted := false
 rwLoop:
 	for {
 		select {
 		case <-ctrlC:
+			interrupted = true
 			cancel()
 			break rwLoop
+		case <-redraw:
+			board.Draw()
+		case now, ok := <-ticks:
+			if !ok {
+				ticks = nil
+			} else {
+				advance(now)
+			}
+		case reload := <-reloads:
+			if reload.Err != nil {
+				log.Print(reload.Err)
+			} else if err := reg.CheckConfig(reload.id, reload.Config); err != nil {
+				log.Printf("config change rejected: %v", err)
+			} else {
+				digestLog(&model.Event{
+					EventType:   model.IncomingEvent,
+					EventID:     model.EventConfigChanged,
+					Time:        clk.Now(),
+					ExtraParams: reload.Config,
+					Race:        reload.id,
+				})
+			}
 		case event, ok := <-events:
 			if !ok {
 				events = nil // remove chan from select-case
 			} else if event != nil {
+				consumed++
 				digestLog(event)
 			}
 		case err, ok := <-errs:
@@ -127,12 +389,126 @@ rwLoop:
 		}
 	}
 
-	for _, e := range m.Disqualified() {
-		printNonNil(e)
+	provisional := interrupted // the race goes on, the start windows stay open
+	if provisional {
+		saveState(reg, journal, *snapshotFile, consumed)
+	} else {
+		for _, e := range reg.Disqualified() {
+			emit(e)
+		}
 	}
 
-	fmt.Println("### Resulting Report ###")
-	for _, c := range m.GetReport() {
-		fmt.Println(c)
+	if board != nil {
+		if !interrupted {
+			board.SetStatus("The input has ended, Ctrl+C to exit")
+			board.Draw()
+			<-ctrlC
+			interrupted = true
+		}
+		board.Close()
+		log.SetOutput(os.Stderr)
+	}
+
+	last := reg.LastEvent()
+	for _, id := range reg.IDs() {
+		reg.Do(id, func(r *race.Race) {
+			fmt.Println(reportHeader(id, provisional, last, r))
+			for _, c := range r.Monitor.GetReport() {
+				fmt.Println(c)
+			}
+		})
 	}
+
+	if *htmlFile != "" {
+		writeHTML(reg, *htmlFile)
+	}
+	if *pdfFile != "" {
+		writePDF(reg, *pdfFile)
+	}
+
+	if *httpAddr != "" && !interrupted {
+		log.Printf("serving the results on %s, Ctrl+C to stop", *httpAddr)
+		<-ctrlC
+	}
+}
+
+func writeHTML(reg *race.Registry, path string) {
+	page, _ := reg.Page(reg.IDs(), 0)
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := report.WriteHTML(f, page); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writePDF(reg *race.Registry, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := report.WritePDF(f, reg.Sheet()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// saveState flushes the journal and saves the snapshot of the interrupted run
+func saveState(reg *race.Registry, journal *provider.Journal, path string, consumed int) {
+	if journal != nil {
+		if err := journal.Sync(); err != nil {
+			log.Print(err)
+		}
+	}
+	if path == "" {
+		return
+	}
+	if err := race.WriteSnapshot(path, reg.Snapshot(consumed)); err != nil {
+		log.Print(err)
+		return
+	}
+	log.Printf("the race state is saved to %s, continue with -resume %s", path, path)
+}
+
+// reportHeader names the status of the results, the report of the interrupted run is provisional
+func reportHeader(id string, provisional bool, at time.Time, r *race.Race) string {
+	status, officialAt := r.Monitor.ResultsStatus()
+	title := status.String() + " Report"
+	if provisional {
+		title = "Provisional Report at " + r.Format().FormatTime(at)
+	}
+	if id != "" {
+		title += ": " + id
+	}
+	if !provisional && status == model.Unofficial && !officialAt.IsZero() {
+		title += ", protests until " + r.Format().FormatTime(officialAt)
+	}
+	return "### " + title + " ###"
+}
+
+// sourceZone is the time zone of the event source by its name, the venue time zone by default
+func sourceZone(conf *config.Config, name string) (tz.Zone, error) {
+	zone := conf.Zone()
+	if name == "" {
+		return zone, nil
+	}
+	var err error
+	if zone.Location, err = tz.Load(name); err != nil {
+		return zone, err
+	}
+	if !zone.IsUTC() && model.IsTimeOnly(conf.Start) {
+		return zone, fmt.Errorf("the source time zone %s needs the start date in the config, the time zone offset depends on it", name)
+	}
+	return zone, nil
+}
+
+type raceFile struct {
+	id, path string
+}
+
+type raceReload struct {
+	id string
+	config.Reload
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+)
+
+const replayHelp = "replay keys: p pause, r resume, space toggle, x <speed>, s <time> seek, then Enter"
+
+// replayKeys controls the replay by the commands typed line by line, the times are in the format
+func replayKeys(ctx context.Context, replay *provider.Replayer, format model.TimeFormat, in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := replayCommand(replay, format, scanner.Text()); err != nil {
+			log.Print(err)
+		}
+	}
+}
+
+func replayCommand(replay *provider.Replayer, format model.TimeFormat, line string) error {
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	arg = strings.TrimSpace(arg)
+	var err error
+	switch {
+	case line != "" && strings.TrimSpace(line) == "": // space
+		if replay.State().Paused {
+			replay.Resume()
+		} else {
+			replay.Pause()
+		}
+	case cmd == "p" || cmd == "pause":
+		replay.Pause()
+	case cmd == "r" || cmd == "resume":
+		replay.Resume()
+	case cmd == "x" || cmd == "speed":
+		var speed float64
+		if speed, err = strconv.ParseFloat(arg, 64); err != nil {
+			return fmt.Errorf("replay speed: %w", err)
+		}
+		err = replay.SetSpeed(speed)
+	case cmd == "s" || cmd == "seek":
+		var t time.Time
+		if t, err = format.ParseDisplayedTime(arg, replay.Now()); err != nil {
+			return err
+		}
+		err = replay.Seek(t)
+	case cmd == "":
+		return nil
+	default:
+		return fmt.Errorf("unknown replay command %q, %s", line, replayHelp)
+	}
+	if err != nil {
+		return err
+	}
+	s := replay.State()
+	log.Printf("replay at %s, %gx, paused: %t", format.FormatTime(s.Position), s.Speed, s.Paused)
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/simulate"
+)
+
+// simulateCmd handles `simulate [flags] <config_file>`: a synthetic event stream of the race
+// written at once or paced like a live timing system
+func simulateCmd(args []string) {
+	p := simulate.DefaultParams
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	flags.IntVar(&p.Athletes, "athletes", p.Athletes, "number of athletes")
+	flags.Float64Var(&p.Speed, "speed", p.Speed, "mean skiing speed [m/s]")
+	flags.Float64Var(&p.SpeedSD, "speed-sd", p.SpeedSD, "standard deviation of the skiing speed [m/s]")
+	flags.Float64Var(&p.PenaltySpeed, "penalty-speed", p.PenaltySpeed, "share of the skiing speed on the penalty loops")
+	flags.Float64Var(&p.HitRate, "hit-rate", p.HitRate, "probability to hit a target")
+	flags.Float64Var(&p.DNF, "dnf", p.DNF, "probability to not finish")
+	flags.Float64Var(&p.LateStart, "late", p.LateStart, "probability to miss the start window")
+	flags.Uint64Var(&p.Seed, "seed", p.Seed, "random seed, the same seed gives the same race")
+	pace := flags.Float64("pace", 0, "emit the events in real time (1) or N times faster (N), 0 writes them at once")
+	out := flags.String("out", "-", "where to write the events: - for stdout, tcp:host:port or unix:path")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s simulate [flags] <config_file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	conf, err := config.LoadConfig(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		network, addr, ok := strings.Cut(*out, ":")
+		if !ok || (network != "tcp" && network != "unix") {
+			log.Fatalf("invalid output %q: must be -, tcp:host:port or unix:path", *out)
+		}
+		conn, err := net.Dial(network, addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+		w = conn
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	var last time.Time
+	for i, e := range simulate.Generate(conf, p) {
+		if *pace > 0 && i > 0 {
+			bw.Flush()
+			time.Sleep(time.Duration(float64(e.Time.Sub(last)) / *pace))
+		}
+		last = e.Time
+		if _, err := fmt.Fprintln(bw, e.Line()); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+)
+
+// standingsCmd handles `standings -at <time> <config_file> <event_file>`: the standings of the race
+// at the race time, e.g. for the disputes
+func standingsCmd(args []string) {
+	flags := flag.NewFlagSet("standings", flag.ExitOnError)
+	at := flags.String("at", "", "race time of the standings as it is displayed, e.g. 09:47:12")
+	sourceTZ := flags.String("source-tz", "", "IANA time zone of the event source, the venue time zone by default")
+	displayUTC := flags.Bool("utc", false, "the times are in UTC instead of the venue time")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s standings -at <time> [flags] <config_file> <event_file>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 || *at == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	conf, err := config.LoadConfig(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	source, err := sourceZone(conf, *sourceTZ)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reg := race.NewRegistry()
+	reg.SetDisplayUTC(*displayUTC)
+	if _, err := reg.Add("", conf); err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.Open(flags.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	events, errs := provider.ScanIn(context.Background(), f, source)
+	for event := range events {
+		if _, err := reg.DigestEvent(event); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := <-errs; err != nil {
+		log.Fatal(err)
+	}
+
+	t, err := reg.Format("").ParseDisplayedTime(*at, reg.LastEvent())
+	if err != nil {
+		log.Fatal(err)
+	}
+	past, err := reg.At(t)
+	if err != nil {
+		log.Fatal(err)
+	}
+	past.Do("", func(r *race.Race) {
+		fmt.Printf("### Standings at %s ###\n", r.Format().FormatTime(t))
+		res := r.Results()
+		if err := report.WriteText(os.Stdout, res); err != nil {
+			log.Fatal(err)
+		}
+		var onRange, inPenalty []string
+		for _, c := range res.Results {
+			switch {
+			case strings.HasPrefix(c.Where, "range"):
+				onRange = append(onRange, fmt.Sprint(c.ID))
+			case c.Where == "penalty":
+				inPenalty = append(inPenalty, fmt.Sprint(c.ID))
+			}
+		}
+		fmt.Printf("On range: %s\nIn penalty: %s\n", strings.Join(onRange, ", "), strings.Join(inPenalty, ", "))
+	})
+}
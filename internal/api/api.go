@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+)
+
+// LiveRefresh is the reload period of the live results pages [s]
+const LiveRefresh = 5
+
+// NewHandler serves the races of the registry:
+//
+//	GET /                     the live results page of all races
+//	GET /races                the races
+//	GET /races/{race}         the live results page of the race
+//	GET /races/{race}/report  the results of the race
+//	GET /report               the results of the only race
+//
+// The pages and the results take ?at=09:47:12 for the standings at that race time.
+func NewHandler(reg *race.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		page(reg, nil, w, r)
+	})
+	mux.HandleFunc("GET /races", func(w http.ResponseWriter, r *http.Request) {
+		races := []RaceInfo{}
+		for _, id := range reg.IDs() {
+			reg.Do(id, func(race *race.Race) {
+				races = append(races, raceInfo(race))
+			})
+		}
+		writeJSON(w, races)
+	})
+	mux.HandleFunc("GET /races/{race}", func(w http.ResponseWriter, r *http.Request) {
+		page(reg, []string{r.PathValue("race")}, w, r)
+	})
+	mux.HandleFunc("GET /races/{race}/report", func(w http.ResponseWriter, r *http.Request) {
+		results(reg, r.PathValue("race"), w, r)
+	})
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		results(reg, "", w, r)
+	})
+	return mux
+}
+
+type RaceInfo struct {
+	ID          string `json:"id"`
+	Laps        int    `json:"laps"`
+	Start       string `json:"start"`
+	Competitors int    `json:"competitors"`
+	Status      string `json:"status"` // of the results
+}
+
+func raceInfo(race *race.Race) RaceInfo {
+	info := RaceInfo{
+		ID:          race.ID,
+		Laps:        race.Config.Laps,
+		Start:       race.Format().FormatTime(race.Config.Start),
+		Competitors: len(race.Monitor.GetReport()),
+	}
+	status, _ := race.Monitor.ResultsStatus()
+	info.Status = status.String()
+	return info
+}
+
+// at returns the races at the race time of the ?at parameter, the live races without it
+func at(reg *race.Registry, w http.ResponseWriter, r *http.Request) (*race.Registry, bool) {
+	s := r.FormValue("at")
+	if s == "" {
+		return reg, true
+	}
+	t, err := reg.Format("").ParseDisplayedTime(s, reg.LastEvent()) // the times of the first race
+	if err == nil {
+		reg, err = reg.At(t)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return reg, true
+}
+
+// page writes the results page of the races, all of them for nil ids
+func page(reg *race.Registry, ids []string, w http.ResponseWriter, r *http.Request) {
+	refresh := LiveRefresh
+	if r.FormValue("at") != "" {
+		refresh = 0
+	}
+	reg, ok := at(reg, w, r)
+	if !ok {
+		return
+	}
+	if ids == nil {
+		ids = reg.IDs()
+	}
+	page, ok := reg.Page(ids, refresh)
+	if !ok {
+		http.Error(w, "unknown race", http.StatusNotFound)
+		return
+	}
+	if s := r.FormValue("at"); s != "" {
+		page.Title += " at " + s
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := report.WriteHTML(w, page); err != nil {
+		log.Printf("api: %v", err)
+	}
+}
+
+func results(reg *race.Registry, id string, w http.ResponseWriter, r *http.Request) {
+	reg, ok := at(reg, w, r)
+	if !ok {
+		return
+	}
+	var res []report.Result
+	if !reg.Do(id, func(race *race.Race) { res = race.Results().Results }) {
+		http.Error(w, "unknown race", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, res)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/api"
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	start, _ := time.Parse(model.TimeLayout, "10:00:00.000")
+	reg := race.NewRegistry()
+	_, err := reg.Add("", &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 0, Start: start, StartDelta: 30 * time.Second})
+	assert.NoError(t, err)
+	for _, line := range []string{
+		"[09:00:00.000] 1 1 Ola Nordmann",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:59:00.000] 3 1",
+		"[09:59:00.000] 3 2",
+		"[10:00:00.000] 4 1",
+		"[10:00:30.000] 4 2",
+		"[10:10:00.000] 10 1",
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		_, err = reg.DigestEvent(event)
+		assert.NoError(t, err)
+	}
+
+	srv := httptest.NewServer(api.NewHandler(reg))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/report")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var results []report.Result
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+	assert.Equal(t, []report.Result{
+		{Rank: 1, ID: 1, Name: "Ola Nordmann", Status: "Finished", Time: "00:10:00.000", Laps: []report.Lap{{Time: "00:10:00.000", Speed: 5}}, Shooting: []int{}},
+		{ID: 2, Status: "Running", Where: "lap 1", Laps: []report.Lap{}, Shooting: []int{}},
+	}, results)
+
+	resp, err = http.Get(srv.URL + "/")
+	assert.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(page), `<meta http-equiv="refresh" content="5">`)
+	assert.Contains(t, string(page), "<td class=\"name\">Ola Nordmann</td>")
+
+	resp, err = http.Get(srv.URL + "/races/relay/report")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+)
+
+// NewReplayHandler controls the replay:
+//
+//	GET  /replay                  the replay state
+//	POST /replay/pause
+//	POST /replay/resume
+//	POST /replay/speed?x=4        times the real pace
+//	POST /replay/seek?t=10:15:00  move forward to the race time, as it is displayed in the format
+func NewReplayHandler(replay *provider.Replayer, format model.TimeFormat) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /replay", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, replayState(replay, format))
+	})
+	mux.HandleFunc("POST /replay/pause", func(w http.ResponseWriter, r *http.Request) {
+		replay.Pause()
+		writeJSON(w, replayState(replay, format))
+	})
+	mux.HandleFunc("POST /replay/resume", func(w http.ResponseWriter, r *http.Request) {
+		replay.Resume()
+		writeJSON(w, replayState(replay, format))
+	})
+	mux.HandleFunc("POST /replay/speed", func(w http.ResponseWriter, r *http.Request) {
+		speed, err := strconv.ParseFloat(r.FormValue("x"), 64)
+		if err == nil {
+			err = replay.SetSpeed(speed)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, replayState(replay, format))
+	})
+	mux.HandleFunc("POST /replay/seek", func(w http.ResponseWriter, r *http.Request) {
+		t, err := format.ParseDisplayedTime(r.FormValue("t"), replay.Now())
+		if err == nil {
+			err = replay.Seek(t)
+		}
+		if errors.Is(err, provider.ErrSeekBack) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, replayState(replay, format))
+	})
+	return mux
+}
+
+type ReplayState struct {
+	Position string  `json:"position"`
+	Speed    float64 `json:"speed"`
+	Paused   bool    `json:"paused"`
+}
+
+func replayState(replay *provider.Replayer, format model.TimeFormat) ReplayState {
+	s := replay.State()
+	return ReplayState{Position: format.FormatTime(s.Position), Speed: s.Speed, Paused: s.Paused}
+}
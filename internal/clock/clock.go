@@ -0,0 +1,104 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the race time source. The monitor is advanced to every moment sent by Ticks.
+type Clock interface {
+	Now() time.Time
+	Ticks() <-chan time.Time
+}
+
+// Replay is the event-time clock: the race time moves only with the incoming events
+type Replay struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewReplay() *Replay {
+	return &Replay{}
+}
+
+func (c *Replay) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Observe moves the clock forward to the event time, the clock never goes back
+func (c *Replay) Observe(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Ticks returns nil: there are no moments between the events in replay
+func (c *Replay) Ticks() <-chan time.Time {
+	return nil
+}
+
+// Wall is the live clock: the system clock ticking with the given interval
+type Wall struct {
+	ticks chan time.Time
+	now   func() time.Time
+	since time.Time // the first race day for time-only races
+	dated bool
+	loc   *time.Location
+}
+
+// NewWall creates the live clock in the time zone of the race times,
+// dated clock is for the races with the dated events
+func NewWall(ctx context.Context, interval time.Duration, dated bool, loc *time.Location) *Wall {
+	c := &Wall{
+		ticks: make(chan time.Time, 1),
+		now:   time.Now,
+		since: time.Now().In(loc),
+		dated: dated,
+		loc:   loc,
+	}
+	go func() {
+		defer close(c.ticks)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case c.ticks <- c.Now():
+				default: // the previous tick is not consumed yet, it will be superseded
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// Now returns the time in the same form as the parsed event times
+func (c *Wall) Now() time.Time {
+	now := c.now().In(c.loc)
+	if c.dated {
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+	}
+	days := dayStart(now).Sub(dayStart(c.since)) / (24 * time.Hour)
+	return TimeOfDay(now).AddDate(0, 0, int(days))
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c *Wall) Ticks() <-chan time.Time {
+	return c.ticks
+}
+
+// TimeOfDay returns the time of day on the first race day of the time-only races
+func TimeOfDay(t time.Time) time.Time {
+	return time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
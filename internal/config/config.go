@@ -2,45 +2,262 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/tz"
 )
 
 type Config struct {
+	Name  string   `json:"name,omitempty"`  // Event name for the results sheets, optional
+	Venue string   `json:"venue,omitempty"` // Venue name for the results sheets, optional
+	Jury  []string `json:"jury,omitempty"`  // Jury members signing the official results, optional
+
 	Laps        int           `json:"laps"`        // Amount of laps for main distance
 	LapLen      int           `json:"lapLen"`      // Length of each main lap
 	PenaltyLen  int           `json:"penaltyLen"`  // Length of each penalty lap
 	FiringLines int           `json:"firingLines"` // Number of firing lines per lap
-	Start       time.Time     `json:"start"`       // Planned start time for the first competitor
+	Targets     int           `json:"targets"`     // Number of targets on each firing line, 5 if 0 or unset
+	Start       time.Time     `json:"start"`       // Planned start time for the first competitor, optionally with ISO 8601 date
 	StartDelta  time.Duration `json:"startDelta"`  // Planned interval between starts
+
+	PenaltySpeedMin    float64       `json:"penaltySpeedMin"`    // Slowest plausible speed on penalty laps [m/s], optional, unchecked if unset
+	PenaltySpeedMax    float64       `json:"penaltySpeedMax"`    // Fastest plausible speed on penalty laps [m/s], optional
+	SkippedLoopPenalty time.Duration `json:"skippedLoopPenalty"` // Time penalty for each skipped penalty loop, optional
+	StartReminder      time.Duration `json:"startReminder"`      // Remind competitors not on the start line this long before their start, optional
+	ProtestWindow      time.Duration `json:"protestWindow"`      // The results are official this long after the race is over, optional, at once if unset
+
+	CutOffs    []time.Duration `json:"cutOffs"`    // Lap i must be completed within CutOffs[i] from the planned start, optional
+	PullLapped bool            `json:"pullLapped"` // Pull competitors lapped by others from the race
+
+	TimeZone string         `json:"timezone"` // Venue IANA time zone, the config times are in it, UTC if unset, the start must have the date with it
+	Location *time.Location `json:"-"`        // Loaded venue time zone, nil for UTC
+
+	InputPrecision  Precision `json:"inputPrecision"`  // Fractional second digits of the incoming times, milliseconds by default
+	OutputPrecision Precision `json:"outputPrecision"` // Fractional second digits of the rendered times, milliseconds by default
+	Rounding        Rounding  `json:"rounding"`        // Rounding of the results to the output precision, down by default
+
+	Course *Course `json:"course,omitempty"` // Detailed course definition with per-lap lengths and firing positions, optional
+}
+
+// Zone returns the venue time zone, time-only times take its offset on the start date
+func (c *Config) Zone() tz.Zone {
+	z := tz.Zone{Location: c.Location}
+	if !tz.IsTimeOnly(c.Start) {
+		z.Ref = c.Start
+	}
+	return z
+}
+
+const DefaultPenaltySpeedMax = 10.0 // m/s, faster than any athlete on a penalty loop
+
+// PenaltySpeedRange returns the plausible penalty laps speed range, the default is used for unset upper bound
+func (c *Config) PenaltySpeedRange() (lo, hi float64) {
+	lo, hi = c.PenaltySpeedMin, c.PenaltySpeedMax
+	if hi <= 0 {
+		hi = DefaultPenaltySpeedMax
+	}
+	return lo, hi
+}
+
+// startLayouts are the accepted start time layouts, time-only start is kept in the year 0
+var startLayouts = []string{time.TimeOnly, "2006-01-02T15:04:05", time.DateTime}
+
+func parseStart(s string) (t time.Time, err error) {
+	for _, layout := range startLayouts {
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
 }
 
+func formatStart(t time.Time) string {
+	if tz.IsTimeOnly(t) {
+		return t.Format(time.TimeOnly)
+	}
+	return t.Format("2006-01-02T15:04:05")
+}
+
+// formatDuration renders the duration as HH:MM:SS with the fraction if any
+func formatDuration(d time.Duration) string {
+	str := fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	if frac := d % time.Second; frac != 0 {
+		str += strings.TrimRight(fmt.Sprintf(".%09d", frac), "0")
+	}
+	return str
+}
+
+// parseDuration parses HH:MM:SS[.fff], hours are not limited by a day
+func parseDuration(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("must be HH:MM:SS[.fff], got %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 {
+		return 0, fmt.Errorf("invalid hours in %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid minutes in %q", s)
+	}
+	whole, _, _ := strings.Cut(parts[2], ".")
+	sec, err := time.ParseDuration(parts[2] + "s") // exact decimal fraction
+	if err != nil || sec < 0 || sec >= time.Minute || len(whole) != 2 {
+		return 0, fmt.Errorf("invalid seconds in %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + sec, nil
+}
+
+// configJSON is the config file form: times and durations are strings
+type configJSON struct {
+	Start              string   `json:"start"`
+	StartDelta         string   `json:"startDelta"`
+	SkippedLoopPenalty string   `json:"skippedLoopPenalty,omitempty"`
+	StartReminder      string   `json:"startReminder,omitempty"`
+	ProtestWindow      string   `json:"protestWindow,omitempty"`
+	CutOffs            []string `json:"cutOffs,omitempty"`
+	InputPrecision     string   `json:"inputPrecision,omitempty"`
+	OutputPrecision    string   `json:"outputPrecision,omitempty"`
+	Rounding           string   `json:"rounding,omitempty"`
+	*plainConfig
+}
+
+type plainConfig Config // without the methods
+
+// LoadConfig reads the JSON, YAML or TOML config, the format is detected by the file extension.
+// Every field, the nested ones too, can be overridden by the environment variable, see EnvName.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path) // the config file is small, so `Unmarsal` instead of `Decoder`
 	if err != nil {
 		return nil, err
 	}
-	var config Config
-
-	aux := &struct {
-		Start      string `json:"start"`
-		StartDelta string `json:"startDelta"`
-		*Config
-	}{
-		Config: &config,
-	}
 
-	if err := json.Unmarshal(data, &aux); err != nil {
+	raw, err := decode(path, data)
+	if err != nil {
 		return nil, err
 	}
-	if config.Start, err = time.Parse(time.TimeOnly, aux.Start); err != nil {
+	if err := applyEnv(raw); err != nil {
 		return nil, err
 	}
-	if t, err := time.Parse(time.TimeOnly, aux.StartDelta); err != nil {
+	if data, err = json.Marshal(raw); err != nil {
 		return nil, err
-	} else {
-		config.StartDelta = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
 	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates the JSON config, the errors name the fields,
+// the key errors are reported together with the value errors of the other fields
+func ParseConfig(data []byte) (*Config, error) {
+	raw, keyErr := checkKeys(data)
+	if raw == nil {
+		return nil, keyErr // not a JSON object
+	}
+	fail := func(err error) (*Config, error) {
+		return nil, joinFieldErrors(keyErr, err)
+	}
+	var err error
+
+	var config Config
+	aux := &configJSON{plainConfig: (*plainConfig)(&config)}
 
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fail(fieldError(err))
+	}
+	if config.Location, err = tz.Load(config.TimeZone); err != nil {
+		return fail(&FieldError{"timezone", err.Error()})
+	}
+	if config.Start, err = parseStart(aux.Start); err != nil {
+		return fail(&FieldError{"start", fmt.Sprintf("must be HH:MM:SS or YYYY-MM-DDTHH:MM:SS, got %q", aux.Start)})
+	}
+	config.Start = config.Zone().ToUTC(config.Start)
+	if config.StartDelta, err = parseDuration(aux.StartDelta); err != nil {
+		return fail(&FieldError{"startDelta", err.Error()})
+	}
+	if aux.SkippedLoopPenalty != "" {
+		if config.SkippedLoopPenalty, err = parseDuration(aux.SkippedLoopPenalty); err != nil {
+			return fail(&FieldError{"skippedLoopPenalty", err.Error()})
+		}
+	}
+	if aux.StartReminder != "" {
+		if config.StartReminder, err = parseDuration(aux.StartReminder); err != nil {
+			return fail(&FieldError{"startReminder", err.Error()})
+		}
+	}
+	if aux.ProtestWindow != "" {
+		if config.ProtestWindow, err = parseDuration(aux.ProtestWindow); err != nil {
+			return fail(&FieldError{"protestWindow", err.Error()})
+		}
+	}
+	for i, s := range aux.CutOffs {
+		d, err := parseDuration(s)
+		if err != nil {
+			return fail(&FieldError{fmt.Sprintf("cutOffs[%d]", i), err.Error()})
+		}
+		config.CutOffs = append(config.CutOffs, d)
+	}
+	if aux.InputPrecision != "" {
+		if config.InputPrecision, err = ParsePrecision(aux.InputPrecision); err != nil {
+			return fail(&FieldError{"inputPrecision", err.Error()})
+		}
+	}
+	if aux.OutputPrecision != "" {
+		if config.OutputPrecision, err = ParsePrecision(aux.OutputPrecision); err != nil {
+			return fail(&FieldError{"outputPrecision", err.Error()})
+		}
+	}
+	if aux.Rounding != "" {
+		if config.Rounding, err = ParseRounding(aux.Rounding); err != nil {
+			return fail(&FieldError{"rounding", err.Error()})
+		}
+	}
+
+	if err := config.applyCourse(raw); err != nil {
+		return fail(err)
+	}
+
+	if err := joinFieldErrors(keyErr, config.Validate()); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
+
+// MarshalJSON renders the config in the config file form, the start time is in the venue time zone,
+// with the defaults resolved
+func (c Config) MarshalJSON() ([]byte, error) {
+	_, c.PenaltySpeedMax = c.PenaltySpeedRange()
+	if c.Targets == 0 {
+		c.Targets = DefaultTargets
+	}
+	if c.Rounding == "" {
+		c.Rounding = RoundDown
+	}
+	aux := configJSON{
+		Start:              formatStart(c.Zone().FromUTC(c.Start)),
+		StartDelta:         formatDuration(c.StartDelta),
+		SkippedLoopPenalty: formatDuration(c.SkippedLoopPenalty),
+		StartReminder:      formatDuration(c.StartReminder),
+		ProtestWindow:      formatDuration(c.ProtestWindow),
+		InputPrecision:     c.InputPrecision.String(),
+		OutputPrecision:    c.OutputPrecision.String(),
+		Rounding:           string(c.Rounding),
+		plainConfig:        (*plainConfig)(&c),
+	}
+	if c.SkippedLoopPenalty == 0 {
+		aux.SkippedLoopPenalty = ""
+	}
+	if c.StartReminder == 0 {
+		aux.StartReminder = ""
+	}
+	if c.ProtestWindow == 0 {
+		aux.ProtestWindow = ""
+	}
+	for _, d := range c.CutOffs {
+		aux.CutOffs = append(aux.CutOffs, formatDuration(d))
+	}
+	return json.Marshal(aux)
+}
@@ -1,7 +1,9 @@
 package config_test
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -32,3 +34,204 @@ func TestConfig(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, configOk, *configLoaded)
 }
+
+func TestConfigDatedStart(t *testing.T) {
+	const json = `{"laps": 2, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "2025-03-01T23:50:00", "startDelta": "00:00:30"}`
+
+	tmpFile, err := os.CreateTemp("", "testfile-*.json")
+	assert.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(json)
+	assert.NoError(t, err)
+
+	configLoaded, err := config.LoadConfig(tmpFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 23, 50, 0, 0, time.UTC), configLoaded.Start)
+}
+
+func TestConfigFormats(t *testing.T) {
+	configOk := config.Config{
+		Laps:        2,
+		LapLen:      3651,
+		PenaltyLen:  50,
+		FiringLines: 1,
+		Start:       time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC),
+		StartDelta:  30 * time.Second,
+		CutOffs:     []time.Duration{20 * time.Minute},
+	}
+
+	for ext, content := range map[string]string{
+		".yaml": "laps: 2\nlapLen: 3651\npenaltyLen: 50\nfiringLines: 1\nstart: \"09:30:00\"\nstartDelta: \"00:00:30\"\ncutOffs: [\"00:20:00\"]\n",
+		".toml": "# sprint\nlaps = 2\nlapLen = 3_651\npenaltyLen = 50 # meters\nfiringLines = 1\nstart = 09:30:00\nstartDelta = \"00:00:30\"\ncutOffs = [\"00:20:00\"]\n",
+	} {
+		t.Run(ext, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "testfile-*"+ext)
+			assert.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.WriteString(content)
+			assert.NoError(t, err)
+
+			configLoaded, err := config.LoadConfig(tmpFile.Name())
+			assert.NoError(t, err)
+			assert.Equal(t, configOk, *configLoaded)
+		})
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	const json = `{"laps" : 2, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00", "startDelta": "00:00:30"}`
+
+	tmpFile, err := os.CreateTemp("", "testfile-*.json")
+	assert.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(json)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "BIATHLON_PENALTY_LEN", config.EnvName("penaltyLen"))
+	t.Setenv("BIATHLON_PENALTY_LEN", "150")
+	t.Setenv("BIATHLON_START", "10:00:00")
+	t.Setenv("BIATHLON_PULL_LAPPED", "true")
+	assert.Equal(t, "BIATHLON_COURSE_PENALTY_LEN", config.EnvName("course.penaltyLen"))
+	t.Setenv("BIATHLON_COURSE_PENALTY_LEN", "150")
+	t.Setenv("BIATHLON_COURSE_LAPS", `[{"length": 3000, "firing": {"position": "prone"}}, {"length": 3200}]`)
+
+	configLoaded, err := config.LoadConfig(tmpFile.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, 150, configLoaded.PenaltyLen)
+	assert.Equal(t, time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC), configLoaded.Start)
+	assert.True(t, configLoaded.PullLapped)
+	assert.Equal(t, 150, configLoaded.Course.PenaltyLen)
+	assert.Equal(t, 3200, configLoaded.LapLength(1))
+	assert.Equal(t, 1, configLoaded.FiringLineOfLap(0))
+
+	t.Setenv("BIATHLON_COURSE_LAPS_LENGTH", "3000")
+	_, err = config.LoadConfig(tmpFile.Name())
+	assert.EqualError(t, err, "BIATHLON_COURSE_LAPS_LENGTH: unknown config key")
+}
+
+func TestConfigValidation(t *testing.T) {
+	const base = `"laps": 2, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00"`
+	for _, test := range []struct {
+		json string
+		err  string
+	}{
+		{json: `{` + base + `, "startDelta": "00:00:15.5"}`},
+		{json: `{` + base + `, "startDelta": "25:00:00"}`},
+		{json: `{"laps": 0, "lapLen": -1, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00", "startDelta": "00:00:30"}`,
+			err: "laps: must be ≥ 1\nlapLen: must be ≥ 1"},
+		{json: `{` + base + `, "startDelta": "00:00:30", "lapLength": 1}`, err: "lapLength: unknown key"},
+		{json: `{"laps": 0, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00", "startDelta": "00:00:30", "lapLength": 1}`,
+			err: "lapLength: unknown key\nlaps: must be ≥ 1"},
+		{json: `{"start": "09:30:00", "startDelta": "00:00:30", "course": {"penaltyLen": 50, "laps": [{"length": 3651, "bogus": 1}]}}`,
+			err: "course.laps[0].bogus: unknown key"},
+		{json: `{"start": "09:30:00", "startDelta": "00:00:30", "course": {"penaltyLen": 0, "laps": [{"length": 3651, "firing": {"position": "prone", "shots": 5}}]}}`,
+			err: "course.laps[0].firing.shots: unknown key\npenaltyLen: must be ≥ 1\ncourse.penaltyLen: must be ≥ 1"},
+		{json: `{"laps": 2, "start": "09:30:00"}`,
+			err: "firingLines: is required\nlapLen: is required\npenaltyLen: is required\nstartDelta: is required"},
+		{json: `{` + base + `, "startDelta": "00:60:00"}`, err: `startDelta: invalid minutes in "00:60:00"`},
+		{json: `{` + base + `, "startDelta": "00:00:00"}`, err: "startDelta: must be positive"},
+		{json: `{"laps": "two", "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00", "startDelta": "00:00:30"}`,
+			err: "laps: must be int, got string"},
+		{json: `{` + base + `, "startDelta": "00:00:30", "cutOffs": ["00:20:00", "00:10:00"]}`,
+			err: "cutOffs[1]: must be after the previous lap cut-off"},
+		{json: `{` + base + `, "startDelta": "00:00:30", "outputPrecision": "seconds"}`,
+			err: `outputPrecision: must be tenths, hundredths, milliseconds or microseconds, got "seconds"`},
+		{json: `{` + base + `, "startDelta": "00:00:30", "targets": -1}`, err: "targets: must be ≥ 0"},
+		{json: `{` + base + `, "startDelta": "00:00:30", "targets": 0}`},
+		{json: `{` + base + `, "startDelta": "00:00:30", "timezone": "Europe/Oslo"}`,
+			err: "start: must have the date with the timezone, the time zone offset depends on it"},
+	} {
+		t.Run(test.json, func(t *testing.T) {
+			conf, err := config.ParseConfig([]byte(test.json))
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, conf)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+	}
+	assert.NoError(t, json.Unmarshal(config.Schema, &schema))
+	for _, key := range config.Keys() {
+		assert.Contains(t, schema.Properties, key)
+	}
+	assert.Len(t, schema.Properties, len(config.Keys()))
+}
+
+func TestConfigCourse(t *testing.T) {
+	const json = `{"start": "10:00:00", "startDelta": "00:00:30", "course": {"penaltyLen": 150, "laps": [
+		{"length": 3300, "firing": {"position": "prone", "targets": 5}},
+		{"length": 3300, "firing": {"position": "standing", "targets": 5}},
+		{"length": 3400}]}}`
+
+	conf, err := config.ParseConfig([]byte(json))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, conf.Laps)
+	assert.Equal(t, 2, conf.FiringLines)
+	assert.Equal(t, 150, conf.PenaltyLen)
+	assert.Equal(t, 3400, conf.LapLength(2))
+	assert.Equal(t, 2, conf.FiringLineOfLap(1))
+	assert.Equal(t, 0, conf.FiringLineOfLap(2))
+
+	_, err = config.ParseConfig([]byte(`{"start": "10:00:00", "startDelta": "00:00:30", "laps": 2,
+		"course": {"penaltyLen": 150, "laps": [{"length": 3300, "firing": {"position": "kneeling", "targets": 5}}]}}`))
+	assert.EqualError(t, err, "laps: must agree with the course (1), got 2")
+
+	_, err = config.ParseConfig([]byte(`{"start": "10:00:00", "startDelta": "00:00:30",
+		"course": {"penaltyLen": 150, "laps": [{"length": 3300, "firing": {"position": "kneeling", "targets": 5}}]}}`))
+	assert.EqualError(t, err, `course.laps[0].firing.position: must be prone or standing, got "kneeling"`)
+
+	const toml = `start = 10:00:00
+startDelta = "00:00:30"
+
+[course]
+penaltyLen = 150
+
+[[course.laps]]
+length = 3300
+firing = { position = "prone", targets = 5 }
+
+[[course.laps]]
+length = 3400
+`
+	path := filepath.Join(t.TempDir(), "course.toml")
+	assert.NoError(t, os.WriteFile(path, []byte(toml), 0o644))
+	conf, err = config.LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, conf.Laps)
+	assert.Equal(t, 1, conf.FiringLines)
+	assert.Equal(t, 3400, conf.LapLength(1))
+
+	data, err := conf.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"targets":5`, "the defaults are resolved")
+}
+
+func TestConfigUpdate(t *testing.T) {
+	conf, err := config.ParseConfig([]byte(`{"laps": 2, "lapLen": 3651, "penaltyLen": 50, "firingLines": 1, "start": "09:30:00", "startDelta": "00:00:30"}`))
+	assert.NoError(t, err)
+
+	next := *conf
+	next.PenaltyLen = 150
+	next.Laps = 3
+	assert.NoError(t, conf.CheckUpdate(&next, config.RaceState{Events: true, Started: true, Fired: true}))
+
+	err = conf.CheckUpdate(&next, config.RaceState{Events: true, Started: true, Fired: true, Finished: true})
+	assert.EqualError(t, err, "laps: can not be changed after a finish")
+
+	next = *conf
+	next.Targets = 3
+	next.StartDelta = time.Minute
+	assert.NoError(t, conf.CheckUpdate(&next, config.RaceState{Events: true}))
+	assert.EqualError(t, conf.CheckUpdate(&next, config.RaceState{Events: true, Started: true, Fired: true}),
+		"startDelta: can not be changed after the first start\ntargets: can not be changed after the first shot")
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Course is the detailed course definition, it overrides laps, lapLen, penaltyLen and firingLines
+type Course struct {
+	Laps       []CourseLap `json:"laps"`       // Main distance laps in order
+	PenaltyLen int         `json:"penaltyLen"` // Length of each penalty lap
+}
+
+type CourseLap struct {
+	Length int         `json:"length"`           // Length of the lap
+	Firing *FiringLine `json:"firing,omitempty"` // Firing line of the lap, none on the last lap usually
+}
+
+type FiringLine struct {
+	Position string `json:"position"`          // prone or standing
+	Targets  int    `json:"targets,omitempty"` // Number of targets, the config targets if 0 or unset
+}
+
+const (
+	Prone    = "prone"
+	Standing = "standing"
+)
+
+// LapLength returns the length of the lap i counted from 0
+func (c *Config) LapLength(i int) int {
+	if c.Course != nil && i < len(c.Course.Laps) {
+		return c.Course.Laps[i].Length
+	}
+	return c.LapLen
+}
+
+// FiringLineOfLap returns the number of the firing line on the lap i counted from 0
+// by the course definition, 0 if the lap has no firing line
+func (c *Config) FiringLineOfLap(i int) int {
+	if c.Course == nil || i >= len(c.Course.Laps) || c.Course.Laps[i].Firing == nil {
+		return 0
+	}
+	line := 0
+	for _, lap := range c.Course.Laps[:i+1] {
+		if lap.Firing != nil {
+			line++
+		}
+	}
+	return line
+}
+
+const DefaultTargets = 5 // targets on each firing line by default
+
+// TargetsOnLine returns the number of targets on the firing line counted from 1
+func (c *Config) TargetsOnLine(line int) int {
+	if c.Course != nil {
+		n := 0
+		for _, lap := range c.Course.Laps {
+			if lap.Firing == nil {
+				continue
+			}
+			if n++; n == line && lap.Firing.Targets > 0 {
+				return lap.Firing.Targets
+			}
+		}
+	}
+	if c.Targets > 0 {
+		return c.Targets
+	}
+	return DefaultTargets
+}
+
+// Shots returns the total number of shots on the first lines firing lines
+func (c *Config) Shots(lines int) int {
+	shots := 0
+	for line := 1; line <= lines; line++ {
+		shots += c.TargetsOnLine(line)
+	}
+	return shots
+}
+
+// MaxTargets returns the largest number of targets on a firing line
+func (c *Config) MaxTargets() int {
+	targets := c.TargetsOnLine(1)
+	for line := 2; line <= c.FiringLines; line++ {
+		targets = max(targets, c.TargetsOnLine(line))
+	}
+	return targets
+}
+
+// applyCourse derives the plain fields from the course, the given plain fields must agree with it
+func (c *Config) applyCourse(raw map[string]json.RawMessage) error {
+	if c.Course == nil {
+		return nil
+	}
+	lines := 0
+	for _, lap := range c.Course.Laps {
+		if lap.Firing != nil {
+			lines++
+		}
+	}
+	lapLen := 0
+	if len(c.Course.Laps) > 0 {
+		lapLen = c.Course.Laps[0].Length
+	}
+
+	for _, derived := range []struct {
+		key   string
+		field *int
+		value int
+	}{
+		{"laps", &c.Laps, len(c.Course.Laps)},
+		{"lapLen", &c.LapLen, lapLen},
+		{"penaltyLen", &c.PenaltyLen, c.Course.PenaltyLen},
+		{"firingLines", &c.FiringLines, lines},
+	} {
+		// lapLen is only the fallback length, it may differ from the first lap
+		if _, ok := raw[derived.key]; ok && *derived.field != derived.value && derived.key != "lapLen" {
+			return &FieldError{derived.key, fmt.Sprintf("must agree with the course (%d), got %d", derived.value, *derived.field)}
+		}
+		if _, ok := raw[derived.key]; !ok {
+			*derived.field = derived.value
+		}
+	}
+	return nil
+}
+
+func (course *Course) validate(check func(ok bool, path, msg string)) {
+	check(len(course.Laps) >= 1, "course.laps", "must have at least one lap")
+	check(course.PenaltyLen >= 1, "course.penaltyLen", "must be ≥ 1")
+	for i, lap := range course.Laps {
+		path := fmt.Sprintf("course.laps[%d]", i)
+		check(lap.Length >= 1, path+".length", "must be ≥ 1")
+		if lap.Firing != nil {
+			check(lap.Firing.Position == Prone || lap.Firing.Position == Standing, path+".firing.position",
+				fmt.Sprintf("must be prone or standing, got %q", lap.Firing.Position))
+			check(lap.Firing.Targets >= 0, path+".firing.targets", "must be ≥ 0")
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const EnvPrefix = "BIATHLON_"
+
+// decode reads the config file into the generic form by the file extension, JSON by default
+func decode(path string, data []byte) (map[string]any, error) {
+	raw := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("yaml config: %w", err)
+		}
+	case ".toml":
+		var err error
+		if raw, err = parseTOML(data); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	}
+	if raw == nil { // empty yaml document
+		raw = make(map[string]any)
+	}
+	return raw, nil
+}
+
+// EnvName returns the environment variable overriding the config field, e.g. lapLen -> BIATHLON_LAP_LEN,
+// the fields of the nested objects are named by their paths, e.g. course.penaltyLen -> BIATHLON_COURSE_PENALTY_LEN
+func EnvName(key string) string {
+	var sb strings.Builder
+	sb.WriteString(EnvPrefix)
+	for i, r := range key {
+		if r == '.' {
+			sb.WriteByte('_')
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// Keys returns the config file keys
+func Keys() []string {
+	var keys []string
+	for key := range fieldsOf(reflect.TypeOf(Config{})) {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// fieldsOf yields the JSON keys of the struct fields with their types
+func fieldsOf(t reflect.Type) iter.Seq2[string, reflect.Type] {
+	return func(yield func(string, reflect.Type) bool) {
+		for i := range t.NumField() {
+			key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if key != "" && key != "-" && !yield(key, t.Field(i).Type) {
+				return
+			}
+		}
+	}
+}
+
+// keyPaths returns the keys of the struct with the paths of the nested object keys, like course.penaltyLen
+func keyPaths(prefix string, t reflect.Type) []string {
+	var paths []string
+	for key, field := range fieldsOf(t) {
+		path := prefix + key
+		paths = append(paths, path)
+		if field.Kind() == reflect.Pointer {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct {
+			paths = append(paths, keyPaths(path+".", field)...)
+		}
+	}
+	return paths
+}
+
+// applyEnv overrides the config fields by the environment variables, the values are JSON
+// (numbers, booleans, arrays, objects) or plain strings. The nested objects are created as needed,
+// the array items are only overridden with the whole array. The unknown BIATHLON_ variables are rejected.
+func applyEnv(raw map[string]any) error {
+	paths := make(map[string]string)
+	for _, path := range keyPaths("", reflect.TypeOf(Config{})) {
+		paths[EnvName(path)] = path
+	}
+	var names []string
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, EnvPrefix) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names) // the objects go before their fields
+
+	var errs []error
+	for _, name := range names {
+		path, ok := paths[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown config key", name))
+			continue
+		}
+		value := os.Getenv(name)
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		if err := setPath(raw, path, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setPath sets the value by the dotted key path
+func setPath(raw map[string]any, path string, v any) error {
+	parent, key, nested := strings.Cut(path, ".")
+	if !nested {
+		raw[path] = v
+		return nil
+	}
+	if raw[parent] == nil {
+		raw[parent] = make(map[string]any)
+	}
+	object, ok := raw[parent].(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s is not an object", parent)
+	}
+	return setPath(object, key, v)
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Precision is the number of the fractional second digits of the timing
+type Precision int
+
+const (
+	PrecisionDefault Precision = 0 // milliseconds
+	Tenths           Precision = 1
+	Hundredths       Precision = 2
+	Milliseconds     Precision = 3
+	Microseconds     Precision = 6
+)
+
+var precisionNames = map[string]Precision{
+	"tenths":       Tenths,
+	"hundredths":   Hundredths,
+	"milliseconds": Milliseconds,
+	"microseconds": Microseconds,
+}
+
+func (p Precision) Digits() int {
+	if p == PrecisionDefault {
+		return int(Milliseconds)
+	}
+	return int(p)
+}
+
+// Unit is the smallest time step of the precision
+func (p Precision) Unit() time.Duration {
+	unit := time.Second
+	for range p.Digits() {
+		unit /= 10
+	}
+	return unit
+}
+
+func (p Precision) String() string {
+	for name, v := range precisionNames {
+		if v == Precision(p.Digits()) {
+			return name
+		}
+	}
+	return fmt.Sprintf("%d digits", p)
+}
+
+func ParsePrecision(name string) (Precision, error) {
+	v, ok := precisionNames[name]
+	if !ok {
+		return PrecisionDefault, fmt.Errorf("must be tenths, hundredths, milliseconds or microseconds, got %q", name)
+	}
+	return v, nil
+}
+
+// Rounding is how the times are rounded to the output precision
+type Rounding string
+
+const (
+	RoundDown    Rounding = "down" // official: the times are truncated
+	RoundNearest Rounding = "nearest"
+)
+
+func ParseRounding(name string) (Rounding, error) {
+	if r := Rounding(name); r == RoundDown || r == RoundNearest {
+		return r, nil
+	}
+	return "", fmt.Errorf("must be down or nearest, got %q", name)
+}
+
+// Round rounds the duration to the precision unit
+func (r Rounding) Round(d time.Duration, p Precision) time.Duration {
+	if r == RoundNearest {
+		return d.Round(p.Unit())
+	}
+	return d.Truncate(p.Unit())
+}
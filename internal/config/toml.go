@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseTOML decodes the TOML config with the tables, e.g. the course, into the JSON config form
+func parseTOML(data []byte) (map[string]any, error) {
+	raw := make(map[string]any)
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, fmt.Errorf("toml config: %w", err)
+	}
+	tomlTimes(raw)
+	return raw, nil
+}
+
+// tomlTimes renders the TOML local times and dates as the strings of the other config formats
+func tomlTimes(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = tomlTimes(item)
+		}
+	case []map[string]any: // array of tables
+		for _, item := range v {
+			tomlTimes(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = tomlTimes(item)
+		}
+	case time.Time:
+		switch v.Location().String() { // the decoder marks the local values by the zone name
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// RaceState is how far the race has gone, it decides which config changes are safe
+type RaceState struct {
+	Events   bool // any event was digested
+	Started  bool // any competitor has started
+	Fired    bool // any competitor has been on a firing line
+	Finished bool // any competitor has finished
+}
+
+// CheckUpdate reports the changes of the next config that are unsafe in the race state.
+// Lengths, penalty checks, reminders, lapping rule and output settings are safe any time.
+func (c *Config) CheckUpdate(next *Config, state RaceState) error {
+	var errs []error
+	check := func(changed, unsafe bool, key, since string) {
+		if changed && unsafe {
+			errs = append(errs, &FieldError{key, "can not be changed " + since})
+		}
+	}
+
+	check(c.TimeZone != next.TimeZone, state.Events, "timezone", "after the first event")
+	check(c.InputPrecision.Digits() != next.InputPrecision.Digits(), state.Events, "inputPrecision", "after the first event")
+	check(!c.Start.Equal(next.Start), state.Started, "start", "after the first start")
+	check(c.StartDelta != next.StartDelta, state.Started, "startDelta", "after the first start")
+	check(!slices.Equal(c.CutOffs, next.CutOffs), state.Started, "cutOffs", "after the first start")
+	check(!slices.Equal(c.lineTargets(), next.lineTargets()), state.Fired, "targets", "after the first shot")
+	check(c.Laps != next.Laps, state.Finished, "laps", "after a finish")
+	check(c.FiringLines != next.FiringLines, state.Finished, "firingLines", "after a finish")
+	check(!slices.Equal(c.firingPositions(), next.firingPositions()), state.Finished, "course", "firing lines after a finish")
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) lineTargets() []int {
+	var targets []int
+	for line := 1; line <= max(c.FiringLines, 1); line++ {
+		targets = append(targets, c.TargetsOnLine(line))
+	}
+	return targets
+}
+
+// firingPositions is the course layout: the firing position of each lap, "" for none
+func (c *Config) firingPositions() []string {
+	if c.Course == nil {
+		return nil
+	}
+	positions := make([]string, len(c.Course.Laps))
+	for i, lap := range c.Course.Laps {
+		if lap.Firing != nil {
+			positions[i] = lap.Firing.Position
+		}
+	}
+	return positions
+}
+
+// Watch polls the config file and sends the reloaded config each time the file is modified,
+// the reload errors are sent too and the watching goes on
+func Watch(ctx context.Context, path string, interval time.Duration) <-chan Reload {
+	reloads := make(chan Reload)
+	go func() {
+		defer close(reloads)
+		modTime := modified(path)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if t := modified(path); !t.Equal(modTime) {
+				modTime = t
+				conf, err := LoadConfig(path)
+				if err != nil {
+					err = fmt.Errorf("reload %s: %w", path, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case reloads <- Reload{conf, err}:
+				}
+			}
+		}
+	}()
+	return reloads
+}
+
+type Reload struct {
+	Config *Config
+	Err    error
+}
+
+func modified(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
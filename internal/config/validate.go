@@ -0,0 +1,169 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/GitProger/go-telecom-2025/internal/tz"
+)
+
+// FieldError is a config error of the field, the path is like `laps` or `cutOffs[1]`
+type FieldError struct {
+	Path string
+	Msg  string
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+var (
+	requiredKeys = []string{"start", "startDelta"}
+	courseKeys   = []string{"laps", "lapLen", "penaltyLen", "firingLines"} // required without the course definition
+)
+
+// checkKeys rejects the unknown keys and reports the missing required ones,
+// the map is nil if the config is not a JSON object
+func checkKeys(data []byte) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	errs := unknownKeys("", reflect.TypeOf(Config{}), data)
+	required := requiredKeys
+	if _, ok := raw["course"]; !ok {
+		required = append(courseKeys, requiredKeys...)
+	}
+	for _, key := range required {
+		if _, ok := raw[key]; !ok {
+			errs = append(errs, &FieldError{key, "is required"})
+		}
+	}
+	slices.SortFunc(errs, func(a, b error) int { // map order is random
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return raw, errors.Join(errs...)
+}
+
+// unknownKeys reports the keys of the JSON objects in the data which are not the fields of the type,
+// the values of the wrong types are left to the decoding
+func unknownKeys(path string, t reflect.Type, data json.RawMessage) []error {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return unknownKeys(path, t.Elem(), data)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return nil
+		}
+		var errs []error
+		for i, item := range items {
+			errs = append(errs, unknownKeys(fmt.Sprintf("%s[%d]", path, i), t.Elem(), item)...)
+		}
+		return errs
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(data, &object) != nil {
+			return nil
+		}
+		fields := maps.Collect(fieldsOf(t))
+		var errs []error
+		for name, value := range object {
+			key := name
+			if path != "" {
+				key = path + "." + name
+			}
+			if field, ok := fields[name]; ok {
+				errs = append(errs, unknownKeys(key, field, value)...)
+			} else {
+				errs = append(errs, &FieldError{key, "unknown key"})
+			}
+		}
+		return errs
+	}
+	return nil
+}
+
+// joinFieldErrors joins the errors reporting each field once, by its first error
+func joinFieldErrors(errs ...error) error {
+	var joined []error
+	reported := make(map[string]bool)
+	for _, err := range errs {
+		list := []error{err}
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			list = multi.Unwrap()
+		}
+		for _, err := range list {
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				if reported[fieldErr.Path] {
+					continue
+				}
+				reported[fieldErr.Path] = true
+			}
+			joined = append(joined, err)
+		}
+	}
+	return errors.Join(joined...)
+}
+
+// fieldError names the field of the JSON type error
+func fieldError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &FieldError{typeErr.Field, fmt.Sprintf("must be %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+	return err
+}
+
+// Validate checks the values of the parsed config, all the errors are reported together
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, path, msg string) {
+		if !ok {
+			errs = append(errs, &FieldError{path, msg})
+		}
+	}
+
+	check(c.Laps >= 1, "laps", "must be ≥ 1")
+	check(c.LapLen >= 1, "lapLen", "must be ≥ 1")
+	check(c.PenaltyLen >= 1, "penaltyLen", "must be ≥ 1")
+	check(c.FiringLines >= 0, "firingLines", "must be ≥ 0")
+	check(c.Targets >= 0, "targets", "must be ≥ 0")
+	check(c.StartDelta > 0, "startDelta", "must be positive")
+	check(c.TimeZone == "" || !tz.IsTimeOnly(c.Start), "start", "must have the date with the timezone, the time zone offset depends on it")
+
+	check(c.PenaltySpeedMin >= 0, "penaltySpeedMin", "must be ≥ 0")
+	check(c.PenaltySpeedMax >= 0, "penaltySpeedMax", "must be ≥ 0")
+	if lo, hi := c.PenaltySpeedRange(); lo > 0 {
+		check(lo < hi, "penaltySpeedMin", fmt.Sprintf("must be less than penaltySpeedMax (%g)", hi))
+	}
+	check(c.SkippedLoopPenalty >= 0, "skippedLoopPenalty", "must be ≥ 0")
+	check(c.StartReminder >= 0, "startReminder", "must be ≥ 0")
+	check(c.ProtestWindow >= 0, "protestWindow", "must be ≥ 0")
+
+	if c.Course != nil {
+		c.Course.validate(check)
+	}
+
+	check(len(c.CutOffs) <= c.Laps, "cutOffs", fmt.Sprintf("must have at most %d items, one per lap", c.Laps))
+	for i, d := range c.CutOffs {
+		path := fmt.Sprintf("cutOffs[%d]", i)
+		check(d > 0, path, "must be positive")
+		if i > 0 {
+			check(d > c.CutOffs[i-1], path, "must be after the previous lap cut-off")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//go:embed config.schema.json
+var Schema []byte // JSON Schema of the config file for the editors
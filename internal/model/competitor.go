@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,11 +17,22 @@ const (
 	Started
 	NotFinished
 	Finished
+	Pulled // pulled from the race by a cut-off time or being lapped
 )
 
-func formatDuration(d time.Duration) string {
-	var z time.Time
-	return z.Add(d).Format(TimeLayout)
+var statusNames = [...]string{
+	NotStarted:  "NotStarted",
+	Started:     "Running",
+	NotFinished: "NotFinished",
+	Finished:    "Finished",
+	Pulled:      "Pulled",
+}
+
+func (s CompetitorStatus) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return fmt.Sprintf("CompetitorStatus(%d)", int(s))
+	}
+	return statusNames[s]
 }
 
 type Competitor struct {
@@ -28,8 +40,10 @@ type Competitor struct {
 	Arrived      bool
 	IsFiring     bool
 	Disqualified bool
+	DSQReason    Reason // why the competitor is disqualified
 
-	ID int
+	ID   int
+	Name string // optional, given on the registration
 
 	PenaltyStartTime time.Time
 	LapStartTime     time.Time
@@ -38,11 +52,24 @@ type Competitor struct {
 	StartTime        time.Time
 
 	Status      CompetitorStatus
-	Hits        int // successful hits
-	FiringLines int // total: 5 * lines shots
+	Hits        int   // successful hits
+	FiringLines int   // firing lines passed, the shots are config.Shots(FiringLines)
+	Misses      []int // misses on each passed firing line
 	Laps        []time.Duration
 	PenaltyLaps time.Duration // considered as one lap
-	// totally penalty laps: number of misses = 5 * firing lines - hits
+	TimePenalty time.Duration // added to the total time by the jury
+
+	RangeStartTime time.Time
+	RangeTime      time.Duration // spent on the passed firing lines
+	LapOffCourse   time.Duration // range and penalty time up to the last completed lap
+	LapFiringLines int           // firing lines passed up to the last completed lap
+	Projected      time.Duration // projected total time of the running competitor, 0 if unknown
+
+	RangeStartHits int // hits before the current firing line
+	PenaltyOwed    int // penalty loops to be skied for the misses so far
+	PenaltyLoops   int // penalty loops reported explicitly in the current penalty laps
+	SkippedLoops   int // detected skipped penalty loops
+	// totally penalty laps: number of misses = shots - hits
 }
 
 func NewCompetitor(id int, conf *config.Config) *Competitor {
@@ -53,8 +80,50 @@ func NewCompetitor(id int, conf *config.Config) *Competitor {
 	}
 }
 
+// Format returns the time format of the race of the competitor
+func (c *Competitor) Format() TimeFormat {
+	return FormatOf(c.config)
+}
+
+// OfficialTime is the total time rounded to the output precision by the official rule
+func (c *Competitor) OfficialTime() time.Duration {
+	return c.Format().RoundDuration(c.TotalTime())
+}
+
+// Clone copies the competitor for the snapshots, the copy shares the given config
+func (c *Competitor) Clone(conf *config.Config) *Competitor {
+	clone := *c
+	clone.config = conf
+	clone.Misses = slices.Clone(c.Misses)
+	clone.Laps = slices.Clone(c.Laps)
+	return &clone
+}
+
 func penaltyRange(comp *Competitor) int {
-	return comp.config.PenaltyLen * (comp.FiringLines*5 - comp.Hits)
+	return comp.config.PenaltyLen * (comp.Shots() - comp.Hits)
+}
+
+// PenaltyDistance is the distance of the penalty laps owed for the misses
+func (c *Competitor) PenaltyDistance() int {
+	return penaltyRange(c)
+}
+
+// Speed returns the average speed [m/s] truncated to 3 decimals as in the report
+func Speed(length int, d time.Duration) float64 {
+	return math.Floor(float64(length)/d.Seconds()*1000) / 1000
+}
+
+// PassedHits returns the hits on the passed firing lines, without the one the competitor is firing on
+func (c *Competitor) PassedHits() int {
+	if c.IsFiring {
+		return c.RangeStartHits
+	}
+	return c.Hits
+}
+
+// Shots returns the number of shots on the passed firing lines
+func (c *Competitor) Shots() int {
+	return c.config.Shots(c.FiringLines)
 }
 
 // The final report for each competitor:
@@ -65,22 +134,30 @@ func penaltyRange(comp *Competitor) int {
 // - Average speed over penalty laps [m/s]
 // - Number of hits/number of shots
 // return example: [NotFinished] 1 [{00:29:03.872, 2.093}, {,}] {00:01:44.296, 0.481} 4/5
+// or for the competitor still on course: [Running: lap 2, 00:29:03.872] 1 [{00:29:03.872, 2.093}, {,}] {,} 4/5
 func (c *Competitor) String() string {
+	f := c.Format()
 	var status string
-	if st := c.Status; st == Finished {
-		status = formatDuration(c.TimeFromPlannedStart())
+	if c.Disqualified && c.DSQReason != ReasonNone && c.DSQReason != ReasonLateStart {
+		status = "DSQ: " + c.DSQReason.String()
+	} else if st := c.Status; st == Finished {
+		status = f.FormatDuration(c.TotalTime())
 	} else if st == NotStarted {
 		status = "NotStarted"
 	} else if st == NotFinished {
 		status = "NotFinished"
-	} else if st == Started {
-		panic("can not call .String() on running competitor")
+	} else if st == Pulled {
+		status = "Pulled"
+	} else if st == Started { // in progress: the lap on course and the time at the last completed one
+		status = fmt.Sprintf("Running: lap %d", min(len(c.Laps)+1, c.config.Laps))
+		if len(c.Laps) > 0 {
+			status += ", " + f.FormatDuration(c.TotalTime())
+		}
 	}
 
 	lapStr := func(length int, lapTime time.Duration) string {
 		if lapTime != 0 {
-			sp := float64(length) / lapTime.Seconds()
-			return fmt.Sprintf("{%s, %.3f}", formatDuration(lapTime), math.Floor(sp*1000)/float64(1000))
+			return fmt.Sprintf("{%s, %.3f}", f.FormatDuration(lapTime), Speed(length, lapTime))
 		} else {
 			return "{,}"
 		}
@@ -90,7 +167,7 @@ func (c *Competitor) String() string {
 	sb.WriteByte('[')
 	for i := 0; i < c.config.Laps; i++ {
 		if i < len(c.Laps) {
-			sb.WriteString(lapStr(c.config.LapLen, c.Laps[i]))
+			sb.WriteString(lapStr(c.config.LapLength(i), c.Laps[i]))
 		} else {
 			sb.WriteString("{,}")
 		}
@@ -105,10 +182,19 @@ func (c *Competitor) String() string {
 		c.ID,
 		sb.String(),
 		lapStr(penaltyRange(c), c.PenaltyLaps),
-		c.Hits,
-		c.FiringLines*5)
+		c.PassedHits(),
+		c.Shots())
 }
 
 func (c *Competitor) TimeFromPlannedStart() time.Duration {
 	return c.LapStartTime.Sub(c.PlannedStartTime)
 }
+
+// TotalTime is the time from planned start including the jury time penalties
+func (c *Competitor) TotalTime() time.Duration {
+	t := c.TimeFromPlannedStart()
+	if t > math.MaxInt64-c.TimePenalty { // saturated for those who have not started
+		return t
+	}
+	return t + c.TimePenalty
+}
@@ -30,3 +30,97 @@ func TestCompetitor(t *testing.T) {
 	//       104.296*0.479 = 49.95778
 	// which is way closer, so there is an error in the README.md's example
 }
+
+func TestCompetitorJury(t *testing.T) {
+	config := config.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC),
+		StartDelta:  30 * time.Second,
+	}
+	comp := model.NewCompetitor(2, &config)
+	comp.Status = model.Finished
+	comp.PlannedStartTime = config.Start
+	comp.LapStartTime = config.Start.Add(10 * time.Minute)
+	comp.Laps = []time.Duration{10 * time.Minute}
+	comp.FiringLines = 1
+	comp.Hits = 5
+	comp.TimePenalty = time.Minute
+	assert.Equal(t, "[00:11:00.000] 2 [{00:10:00.000, 5.000}] {,} 5/5", comp.String())
+
+	comp.Disqualified = true
+	comp.DSQReason = model.ReasonMissedPenalty
+	assert.Equal(t, "[DSQ: missed penalty loop] 2 [{00:10:00.000, 5.000}] {,} 5/5", comp.String())
+}
+
+func TestCompetitorPrecision(t *testing.T) {
+	event, err := model.ParseEvent("[10:00:00.123456] 4 1")
+	assert.NoError(t, err)
+	event.Format = model.TimeFormat{Input: config.Microseconds, Output: config.Hundredths}
+	assert.Equal(t, "[10:00:00.12] The competitor(1) has started", event.String())
+
+	event, err = model.ParseEvent("[10:00:00.1234] 4 1") // the finish gate in ten-thousandths
+	assert.NoError(t, err)
+	assert.Error(t, model.TimeFormat{}.CheckInput(event.Time), "finer than milliseconds")
+	assert.NoError(t, model.TimeFormat{Input: config.Microseconds}.CheckInput(event.Time))
+	assert.Equal(t, "[10:00:00.123400] 4 1", (&model.Event{Time: event.Time, EventID: 4, CompetitorID: 1,
+		Format: model.TimeFormat{Input: config.Microseconds}}).Line())
+
+	conf := config.Config{Laps: 1, LapLen: 3000, FiringLines: 1, InputPrecision: config.Microseconds, OutputPrecision: config.Hundredths}
+	comp := model.NewCompetitor(1, &conf)
+	comp.Status = model.Finished
+	comp.PlannedStartTime = tm("10:00:00.000")
+	comp.LapStartTime = comp.PlannedStartTime.Add(10*time.Minute + 9999*time.Millisecond)
+	comp.Laps = []time.Duration{10*time.Minute + 9999*time.Millisecond}
+	assert.Equal(t, "[00:10:09.99] 1 [{00:10:09.99, 4.918}] {,} 0/0", comp.String())
+
+	conf.OutputPrecision, conf.Rounding = config.Tenths, config.RoundNearest
+	assert.Equal(t, "00:10:10.0", comp.Format().FormatDuration(comp.TotalTime()))
+	assert.Equal(t, 10*time.Minute+10*time.Second, comp.OfficialTime())
+}
+
+func TestCompetitorTargets(t *testing.T) {
+	conf := config.Config{
+		Laps:        2,
+		LapLen:      1000,
+		PenaltyLen:  100,
+		FiringLines: 2,
+		Targets:     3,
+	}
+	comp := model.NewCompetitor(1, &conf)
+	comp.Status = model.NotFinished
+	comp.FiringLines = 2
+	comp.Hits = 4
+	comp.PenaltyLaps = 100 * time.Second
+	assert.Equal(t, "[NotFinished] 1 [{,}, {,}] {00:01:40.000, 2.000} 4/6", comp.String())
+
+	conf.Course = &config.Course{PenaltyLen: 100, Laps: []config.CourseLap{
+		{Length: 1000, Firing: &config.FiringLine{Position: config.Prone, Targets: 5}},
+		{Length: 1000, Firing: &config.FiringLine{Position: config.Standing}},
+	}}
+	assert.Equal(t, "[NotFinished] 1 [{,}, {,}] {00:01:40.000, 4.000} 4/8", comp.String())
+}
+
+func TestCompetitorRunning(t *testing.T) {
+	config := config.Config{
+		Laps:        2,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC),
+		StartDelta:  30 * time.Second,
+	}
+	comp := model.NewCompetitor(3, &config)
+	comp.Status = model.Started
+	comp.PlannedStartTime = config.Start
+	comp.LapStartTime = config.Start
+	assert.Equal(t, "[Running: lap 1] 3 [{,}, {,}] {,} 0/0", comp.String())
+
+	comp.LapStartTime = config.Start.Add(10 * time.Minute)
+	comp.Laps = []time.Duration{10 * time.Minute}
+	comp.FiringLines = 1
+	comp.Hits = 5
+	assert.Equal(t, "[Running: lap 2, 00:10:00.000] 3 [{00:10:00.000, 5.000}, {,}] {,} 5/5", comp.String())
+}
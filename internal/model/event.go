@@ -1,17 +1,21 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
 )
 
 const TimeLayout = "15:04:05.000" // like StampMilli in time package
 
 const (
-	EventRegister       = 1  // The competitor registered
+	EventRegister       = 1  // The competitor registered {optional name}
 	EventStartTimeSet   = 2  // The start time was set by a draw {startTime}
 	EventOnStartLine    = 3  // The competitor is on the start line
 	EventStarted        = 4  // The competitor has started
@@ -23,8 +27,19 @@ const (
 	EventLapCompleted   = 10 // The competitor ended the main lap
 	EventCannotContinue = 11 // The competitor can`t continue {comment}
 
-	EventDisqualified = 32 // The competitor is disqualified
-	EventFinished     = 33 // The competitor has finished
+	EventTimePenalty      = 12 // The jury imposed a time penalty {penalty reason}
+	EventJuryDisqualified = 13 // The jury disqualified the competitor {reason}
+	EventReinstated       = 14 // The jury reinstated the competitor
+	EventPenaltyLoop      = 15 // The competitor completed one penalty loop
+	EventConfigChanged    = 16 // The race config is changed, competitor 0 {config JSON}
+
+	EventDisqualified  = 32 // The competitor is disqualified
+	EventFinished      = 33 // The competitor has finished
+	EventPenaltyWarn   = 34 // The competitor's penalty laps look wrong {comment}
+	EventStartReminder = 35 // The competitor is not on the start line yet {startTime}
+	EventPulled        = 36 // The competitor is pulled from the race {comment}
+	EventUnofficial    = 37 // The race is over, the protests are accepted, competitor 0 {officialTime}
+	EventOfficial      = 38 // The results are official, competitor 0
 )
 
 /*
@@ -59,8 +74,19 @@ var eventComms = map[int]string{
 	EventLapCompleted:   "The competitor(%d) ended the main lap",
 	EventCannotContinue: "The competitor(%d) can`t continue: %s",
 
-	EventDisqualified: "The competitor(%d) is disqualified",
-	EventFinished:     "The competitor(%d) has finished",
+	EventTimePenalty:      "The competitor(%d) got a time penalty of %s: %s",
+	EventJuryDisqualified: "The competitor(%d) is disqualified by the jury: %s",
+	EventReinstated:       "The competitor(%d) is reinstated by the jury",
+	EventPenaltyLoop:      "The competitor(%d) completed a penalty loop",
+	EventConfigChanged:    "The race config is changed: %s",
+
+	EventDisqualified:  "The competitor(%d) is disqualified",
+	EventFinished:      "The competitor(%d) has finished",
+	EventPenaltyWarn:   "The competitor(%d) penalty laps warning: %s",
+	EventStartReminder: "The competitor(%d) is not on the start line, the start is at %s",
+	EventPulled:        "The competitor(%d) is pulled from the race: %s",
+	EventUnofficial:    "The results are unofficial, the protests are accepted until %s",
+	EventOfficial:      "The results are official",
 }
 
 type EventType int
@@ -76,42 +102,125 @@ type Event struct {
 	CompetitorID int
 	Time         time.Time
 	ExtraParams  any
+	Race         string // the race of the event when several races are run at once, empty otherwise
+
+	Format TimeFormat // of the race, the times are rendered in UTC unless it is set
 }
 
 func (e *Event) String() string {
 	format := eventComms[e.EventID]
 	var outer string
 	switch e.EventID {
-	case EventStartTimeSet: // competitor number, start time
-		outer = fmt.Sprintf(format, e.CompetitorID, e.ExtraParams.(time.Time).Format(TimeLayout))
+	case EventStartTimeSet, EventStartReminder: // competitor number, start time
+		outer = fmt.Sprintf(format, e.CompetitorID, e.Format.FormatTime(e.ExtraParams.(time.Time)))
 	case EventOnRange: // competitor number, firing range number
 		outer = fmt.Sprintf(format, e.CompetitorID, e.ExtraParams.(int))
 	case EventTargetHit: // target number, competitor number
 		outer = fmt.Sprintf(format, e.ExtraParams.(int), e.CompetitorID)
-	case EventCannotContinue: // competitor numner, comment
+	case EventCannotContinue, EventPenaltyWarn, EventPulled: // competitor numner, comment
 		outer = fmt.Sprintf(format, e.CompetitorID, e.ExtraParams.(string))
+	case EventTimePenalty: // competitor number, penalty, reason
+		d := e.ExtraParams.(JuryDecision)
+		outer = fmt.Sprintf(format, e.CompetitorID, e.Format.FormatDuration(d.Penalty), d.Reason)
+	case EventJuryDisqualified: // competitor number, reason
+		outer = fmt.Sprintf(format, e.CompetitorID, e.ExtraParams.(JuryDecision).Reason)
+	case EventDisqualified: // competitor number, optional reason
+		outer = fmt.Sprintf(format, e.CompetitorID)
+		if r, ok := e.ExtraParams.(Reason); ok && r != ReasonNone {
+			outer += ": " + r.String()
+		}
+	case EventConfigChanged: // config
+		outer = fmt.Sprintf(format, configJSON(e.ExtraParams.(*config.Config)))
+	case EventUnofficial: // official time
+		outer = fmt.Sprintf(format, e.Format.FormatTime(e.ExtraParams.(time.Time)))
+	case EventOfficial:
+		outer = format
 	default: // just competitor number
 		outer = fmt.Sprintf(format, e.CompetitorID)
 	}
-	return fmt.Sprintf("[%s] %s", e.Time.Format(TimeLayout), outer)
+	if e.Race != "" {
+		return fmt.Sprintf("[%s] %s: %s", e.Format.FormatTime(e.Time), e.Race, outer)
+	}
+	return fmt.Sprintf("[%s] %s", e.Format.FormatTime(e.Time), outer)
+}
+
+// Line renders the incoming event in the input format with the internal (UTC) times, for the journal
+func (e *Event) Line() string {
+	line := fmt.Sprintf("[%s] %d %d", e.Format.formatRawTime(e.Time), e.EventID, e.CompetitorID)
+	if e.Race != "" {
+		line = fmt.Sprintf("[%s] %s %d %d", e.Format.formatRawTime(e.Time), e.Race, e.EventID, e.CompetitorID)
+	}
+	switch p := e.ExtraParams.(type) {
+	case nil:
+	case time.Time:
+		line += " " + e.Format.formatRawTime(p)
+	case int, string:
+		line += fmt.Sprintf(" %v", p)
+	case JuryDecision:
+		if e.EventID == EventTimePenalty {
+			line += " " + time.Time{}.Add(p.Penalty).Format(timeLayout(e.Format.Input))
+		}
+		line += " " + string(p.Reason)
+	case *config.Config:
+		line += " " + configJSON(p)
+	}
+	return line
+}
+
+func configJSON(conf *config.Config) string {
+	data, err := json.Marshal(conf)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// RaceID identifies the race day of the event, the races of a multi-day competition are keyed by it
+func (e *Event) RaceID() string {
+	return e.Format.RaceID(e.Time)
+}
+
+// IsRaceID tells the race ID apart from the event ID, the race ID is a word starting with a letter
+// or the race day date, see Event.RaceID
+func IsRaceID(s string) bool {
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return true
+	}
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	}) < 0
 }
 
+// ParseEvent parses the incoming event, the race ID may follow the time: [time] race eventID competitorID extra
 func ParseEvent(line string) (*Event, error) { // Incoming event only
 	var event Event
 	event.EventType = IncomingEvent
 	var tm, extra string
 
+	if stamp, rest, ok := strings.Cut(line, " "); ok {
+		if race, rest, ok := strings.Cut(rest, " "); ok && IsRaceID(race) {
+			event.Race = race
+			line = stamp + " " + rest
+		}
+	}
+
 	n, err := fmt.Sscanf(line, "%s %d %d %s", &tm, &event.EventID, &event.CompetitorID, &extra)
 	if err != nil && (err != io.EOF && n < 4) {
 		return nil, err
 	}
-	if event.Time, err = time.Parse("["+TimeLayout+"]", tm); err != nil {
+	if len(tm) < 2 || tm[0] != '[' || tm[len(tm)-1] != ']' {
+		return nil, fmt.Errorf("event time must be in brackets: %q", tm)
+	}
+	if event.Time, err = ParseTime(tm[1 : len(tm)-1]); err != nil {
 		return nil, err
 	}
 
 	switch event.EventID {
 	case EventStartTimeSet: // start time
-		if event.ExtraParams, err = time.Parse(TimeLayout, extra); err != nil {
+		if event.ExtraParams, err = ParseTime(extra); err != nil {
 			return nil, err
 		}
 	case EventOnRange, EventTargetHit: // firing range number || target number
@@ -121,8 +230,39 @@ func ParseEvent(line string) (*Event, error) { // Incoming event only
 	case EventCannotContinue: // comment
 		parts := strings.SplitN(line, " ", 4) // [time] eventID competitorID comment
 		event.ExtraParams = parts[3]
-	case EventRegister, EventOnStartLine, EventStarted, EventLeftRange, EventEnteredPenalty, EventLeftPenalty, EventLapCompleted:
-	case EventDisqualified, EventFinished: // outgoing event
+	case EventRegister: // optional name
+		if parts := strings.SplitN(line, " ", 4); len(parts) == 4 && strings.TrimSpace(parts[3]) != "" {
+			event.ExtraParams = strings.TrimSpace(parts[3])
+		}
+	case EventConfigChanged: // config JSON
+		parts := strings.SplitN(line, " ", 4) // [time] eventID 0 config
+		if len(parts) < 4 {
+			return nil, fmt.Errorf("config change event expects the config: %q", line)
+		}
+		if event.ExtraParams, err = config.ParseConfig([]byte(parts[3])); err != nil {
+			return nil, err
+		}
+	case EventTimePenalty: // penalty reason
+		fields := strings.Fields(line) // [time] eventID competitorID penalty reason
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("time penalty event expects penalty and reason: %q", line)
+		}
+		var d JuryDecision
+		if d.Penalty, err = parseDuration(fields[3]); err != nil {
+			return nil, err
+		}
+		if d.Reason, err = ParseReason(fields[4]); err != nil {
+			return nil, err
+		}
+		event.ExtraParams = d
+	case EventJuryDisqualified: // reason
+		var d JuryDecision
+		if d.Reason, err = ParseReason(extra); err != nil {
+			return nil, err
+		}
+		event.ExtraParams = d
+	case EventOnStartLine, EventStarted, EventLeftRange, EventEnteredPenalty, EventLeftPenalty, EventLapCompleted, EventReinstated, EventPenaltyLoop:
+	case EventDisqualified, EventFinished, EventPenaltyWarn, EventStartReminder, EventPulled, EventUnofficial, EventOfficial: // outgoing event
 		return nil, fmt.Errorf("outgoing event %d can not be parsed", event.EventID)
 	default: // unknown event
 		return nil, fmt.Errorf("unknown event type: %d", event.EventID)
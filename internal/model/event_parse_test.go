@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -72,6 +73,30 @@ func TestParse(t *testing.T) {
 		{input: input[12], output: &model.Event{Time: tm("09:59:03.872"), EventID: 10, CompetitorID: 1}},
 		{input: input[13], output: &model.Event{Time: tm("09:59:03.872"), EventID: 11, CompetitorID: 1, ExtraParams: "Lost in the forest"}},
 
+		{input: "[10:05:00.000] 12 1 00:01:00 MP", output: &model.Event{Time: tm("10:05:00.000"), EventID: 12, CompetitorID: 1,
+			ExtraParams: model.JuryDecision{Penalty: time.Minute, Reason: model.ReasonMissedPenalty}}},
+		{input: "[10:05:00.000] 13 1 US", output: &model.Event{Time: tm("10:05:00.000"), EventID: 13, CompetitorID: 1,
+			ExtraParams: model.JuryDecision{Reason: model.ReasonUnsporting}}},
+		{input: "[10:05:00.000] 14 1", output: &model.Event{Time: tm("10:05:00.000"), EventID: 14, CompetitorID: 1}},
+
+		{input: "[10:06:00.000] 15 1", output: &model.Event{Time: tm("10:06:00.000"), EventID: 15, CompetitorID: 1}},
+
+		{input: "[2025-03-01T09:15:00.841] 2 1 2025-03-01T09:30:00.000", output: &model.Event{
+			Time: time.Date(2025, 3, 1, 9, 15, 0, 841e6, time.UTC), EventID: 2, CompetitorID: 1,
+			ExtraParams: time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)}},
+
+		{input: "[09:05:59.867] men 1 1", output: &model.Event{Time: tm("09:05:59.867"), EventID: 1, CompetitorID: 1, Race: "men"}},
+		{input: "[09:59:03.872] women-u19 11 2 Lost in the forest", output: &model.Event{Time: tm("09:59:03.872"), EventID: 11, CompetitorID: 2,
+			ExtraParams: "Lost in the forest", Race: "women-u19"}},
+		{input: "[2025-03-02T09:05:59.867] 2025-03-02 1 1", output: &model.Event{
+			Time: time.Date(2025, 3, 2, 9, 5, 59, 867e6, time.UTC), EventID: 1, CompetitorID: 1, Race: "2025-03-02"}},
+
+		{input: "[2025-03-01 09:15:00.841] 1 1", shouldFail: true},
+		{input: "[09:05:59.867] men's 1 1", shouldFail: true},
+		{input: "[10:05:00.000] 12 1 00:01:00", shouldFail: true},
+		{input: "[10:05:00.000] 34 1 skipped", shouldFail: true},
+		{input: "[10:05:00.000] 12 1 1m MP", shouldFail: true},
+		{input: "[10:05:00.000] 13 1 XX", shouldFail: true},
 		{input: "[09:59:03.872] 100 1", shouldFail: true},
 		{input: "[09:59:03.872] 100 abc", shouldFail: true},
 		{input: "[09:59:03.872] 2 1 ", shouldFail: true},
@@ -97,3 +122,24 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatTime(t *testing.T) {
+	event := &model.Event{Time: time.Date(2025, 3, 1, 23, 59, 0, 0, time.UTC), EventID: 2, CompetitorID: 1,
+		ExtraParams: time.Date(2025, 3, 2, 0, 5, 0, 0, time.UTC)}
+	assert.Equal(t, "[2025-03-01T23:59:00.000] The start time for the competitor(1) was set by a draw to 2025-03-02T00:05:00.000", event.String())
+	assert.Equal(t, "2025-03-01", event.RaceID())
+	assert.Equal(t, "day1", model.TimeFormat{}.RaceID(tm("09:00:00.000")))
+
+	event.Race = "men"
+	assert.Equal(t, "[2025-03-01T23:59:00.000] men: The start time for the competitor(1) was set by a draw to 2025-03-02T00:05:00.000", event.String())
+	assert.Equal(t, "[2025-03-01T23:59:00.000] men 2 1 2025-03-02T00:05:00.000", event.Line())
+
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	assert.NoError(t, err)
+	event.Format = model.TimeFormat{Venue: tz.Zone{Location: oslo, Ref: event.Time}}
+	assert.Equal(t, "[2025-03-02T00:59:00.000] men: The start time for the competitor(1) was set by a draw to 2025-03-02T01:05:00.000", event.String())
+	assert.Equal(t, "2025-03-02", event.RaceID(), "the race day is the venue date")
+	assert.Equal(t, "[2025-03-01T23:59:00.000] men 2 1 2025-03-02T00:05:00.000", event.Line(), "the journal is in UTC")
+	event.Format.UTC = true
+	assert.Equal(t, "[2025-03-01T23:59:00.000] men: The start time for the competitor(1) was set by a draw to 2025-03-02T00:05:00.000", event.String())
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type Reason string // jury reason code
+
+const (
+	ReasonNone          Reason = ""
+	ReasonLateStart     Reason = "LS" // did not start in the start interval
+	ReasonMissedPenalty Reason = "MP" // missed penalty loop
+	ReasonCourseCut     Reason = "CC" // course shortcut
+	ReasonEquipment     Reason = "EQ" // equipment violation
+	ReasonUnsporting    Reason = "US" // unsportsmanlike conduct
+	ReasonFalseStart    Reason = "FS" // false start
+	ReasonShooting      Reason = "SH" // shooting range rules breach
+)
+
+var reasonComms = map[Reason]string{
+	ReasonLateStart:     "did not start in the start interval",
+	ReasonMissedPenalty: "missed penalty loop",
+	ReasonCourseCut:     "course shortcut",
+	ReasonEquipment:     "equipment violation",
+	ReasonUnsporting:    "unsportsmanlike conduct",
+	ReasonFalseStart:    "false start",
+	ReasonShooting:      "shooting range rules breach",
+}
+
+func ParseReason(code string) (Reason, error) {
+	r := Reason(code)
+	if _, ok := reasonComms[r]; !ok {
+		return ReasonNone, fmt.Errorf("unknown reason code: %q", code)
+	}
+	return r, nil
+}
+
+func (r Reason) String() string {
+	if comm, ok := reasonComms[r]; ok {
+		return comm
+	}
+	return string(r)
+}
+
+// JuryDecision is the extra params of the jury events (time penalty and disqualification)
+type JuryDecision struct {
+	Penalty time.Duration // zero for disqualification
+	Reason  Reason
+}
+
+// parseDuration parses "HH:MM:SS[.sss]" into a duration
+func parseDuration(s string) (time.Duration, error) {
+	t, err := time.Parse(time.TimeOnly, s) // fractional seconds are accepted while parsing
+	if err != nil {
+		return 0, err
+	}
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())), nil
+}
+
+// ResultsStatus is the stage of the results: provisional while racing, unofficial when the race is over
+// and the protests are accepted, official when the protest window is closed and the results are frozen
+type ResultsStatus int
+
+const (
+	Provisional ResultsStatus = iota
+	Unofficial
+	Official
+)
+
+var resultsStatusNames = [...]string{
+	Provisional: "Provisional",
+	Unofficial:  "Unofficial",
+	Official:    "Official",
+}
+
+func (s ResultsStatus) String() string {
+	if s < 0 || int(s) >= len(resultsStatusNames) {
+		return fmt.Sprintf("ResultsStatus(%d)", int(s))
+	}
+	return resultsStatusNames[s]
+}
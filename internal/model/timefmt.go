@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
+)
+
+const DateTimeLayout = "2006-01-02T" + TimeLayout // ISO 8601 with milliseconds, the default precision
+
+// Times given without a date are kept around the year 0, the race days are counted from its first day.
+
+// TimeFormat renders the times of a race in its venue time zone or in UTC with its timing precision,
+// the zero format is UTC with milliseconds
+type TimeFormat struct {
+	Venue    tz.Zone          // race days and rendered times are in the venue time zone
+	UTC      bool             // render times in UTC instead of the venue time
+	Input    config.Precision // of the incoming times
+	Output   config.Precision // of the rendered times and results
+	Rounding config.Rounding  // of the results to the output precision, down by default
+}
+
+// FormatOf is the time format of the race config, in the venue time
+func FormatOf(conf *config.Config) TimeFormat {
+	if conf == nil {
+		return TimeFormat{}
+	}
+	return TimeFormat{Venue: conf.Zone(), Input: conf.InputPrecision, Output: conf.OutputPrecision, Rounding: conf.Rounding}
+}
+
+// timeLayout is TimeLayout with the given precision
+func timeLayout(p config.Precision) string {
+	return "15:04:05." + strings.Repeat("0", p.Digits())
+}
+
+// RoundDuration rounds the result time to the output precision by the official rule
+func (f TimeFormat) RoundDuration(d time.Duration) time.Duration {
+	return f.Rounding.Round(d, f.Output)
+}
+
+// FormatDuration renders the duration rounded to the output precision
+func (f TimeFormat) FormatDuration(d time.Duration) string {
+	var z time.Time
+	return z.Add(f.RoundDuration(d)).Format(timeLayout(f.Output))
+}
+
+// CheckInput reports the incoming time finer than the input precision
+func (f TimeFormat) CheckInput(t time.Time) error {
+	if t.Nanosecond()%int(f.Input.Unit()) != 0 {
+		return fmt.Errorf("time %s is finer than the input precision, %s", f.formatRawTime(t), f.Input)
+	}
+	return nil
+}
+
+func IsTimeOnly(t time.Time) bool {
+	return tz.IsTimeOnly(t)
+}
+
+// ParseTime parses both time-only and dated times with one to nine fractional second digits,
+// the race checks them against its input precision, see TimeFormat.CheckInput
+func ParseTime(s string) (time.Time, error) {
+	if _, frac, ok := strings.Cut(s, "."); ok && frac != "" {
+		if t, err := time.Parse("15:04:05.999999999", s); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse("2006-01-02T15:04:05.999999999", s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("time %q is neither 15:04:05.fff nor 2006-01-02T15:04:05.fff", s)
+}
+
+// FormatTime formats the time in the same form it was given, in the venue time or UTC
+func (f TimeFormat) FormatTime(t time.Time) string {
+	if !f.UTC {
+		t = f.Venue.FromUTC(t)
+	}
+	layout := timeLayout(f.Output)
+	if IsTimeOnly(t) {
+		return t.Format(layout)
+	}
+	return t.Format("2006-01-02T" + layout)
+}
+
+// RaceID identifies the race day of the time: the venue date or the race day number for time-only input
+func (f TimeFormat) RaceID(t time.Time) string {
+	t = f.Venue.FromUTC(t)
+	if IsTimeOnly(t) {
+		first := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+		since := t.Sub(first)
+		day := since / (24 * time.Hour)
+		if since%(24*time.Hour) < 0 { // floor for the day before the first one
+			day -= 1
+		}
+		return fmt.Sprintf("day%d", day+1)
+	}
+	return t.Format(time.DateOnly)
+}
+
+// OnDay moves the time of day t to the date of the day
+func OnDay(t, day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), day.Location())
+}
+
+// formatRawTime formats the internal time in the input precision without the time zone conversion
+func (f TimeFormat) formatRawTime(t time.Time) string {
+	layout := timeLayout(f.Input)
+	if IsTimeOnly(t) {
+		return t.Format(layout)
+	}
+	return t.Format("2006-01-02T" + layout)
+}
+
+// ParseDisplayedTime parses the time as it is rendered, in the venue time or UTC, with any precision,
+// a time of day is taken on the race day of the time near
+func (f TimeFormat) ParseDisplayedTime(s string, near time.Time) (time.Time, error) {
+	zone := f.Venue
+	if f.UTC {
+		zone = tz.Zone{}
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
+		return zone.ToUTC(t), nil
+	}
+	t, err := time.Parse(time.TimeOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time %q is neither 15:04:05 nor 2006-01-02T15:04:05", s)
+	}
+	if !near.IsZero() {
+		day := zone.FromUTC(near)
+		t = time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return zone.ToUTC(t), nil
+}
@@ -1,7 +1,10 @@
 package monitor
 
 import (
+	"container/heap"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/GitProger/go-telecom-2025/internal/config"
@@ -10,16 +13,29 @@ import (
 )
 
 type EventMonitor interface {
-	DigestEvent(event *model.Event) (*model.Event, error)
+	DigestEvent(event *model.Event) ([]*model.Event, error) // the events of unknown competitors fail with ErrUnknownCompetitor
 	GetReport() []*model.Competitor
 	Disqualified() []*model.Event
+	Advance(now time.Time) []*model.Event
+	CheckConfig(next *config.Config) error
+	Config() *config.Config
+	// ResultsStatus returns the stage of the results and when the unofficial results become official, zero if never
+	ResultsStatus() (model.ResultsStatus, time.Time)
+	Snapshot() EventMonitor // an independent copy of the race state
 }
 
+// ErrUnknownCompetitor is returned for the events of the competitors who have not registered
+var ErrUnknownCompetitor = errors.New("unknown competitor")
+
+// ErrResultsOfficial is returned for the events after the results are official and frozen
+var ErrResultsOfficial = errors.New("the results are official")
+
 type monitor struct {
-	lastTime     time.Time
-	disqualified []int
-	delta        time.Duration
-	defaultStart time.Time
+	lastTime time.Time
+	timers   timerQueue
+
+	results    model.ResultsStatus
+	officialAt time.Time // when the unofficial results become official
 
 	conf    *config.Config
 	service *service.CompetitorService
@@ -32,20 +48,42 @@ func NewEventMonitor(conf *config.Config) *monitor {
 	}
 }
 
-func (em *monitor) DigestEvent(event *model.Event) (*model.Event, error) {
-	if em.delta == 0 {
-		em.delta = em.conf.StartDelta
-		em.defaultStart = em.conf.Start
+func (em *monitor) DigestEvent(event *model.Event) ([]*model.Event, error) {
+	out := em.Advance(event.Time)
+	if em.results == model.Official {
+		return out, fmt.Errorf("event %d of competitor %d: %w", event.EventID, event.CompetitorID, ErrResultsOfficial)
 	}
+	if err := em.checkInput(event); err != nil {
+		return out, err
+	}
+	digested, err := em.digest(event)
+	out = append(out, digested...)
+	if err == nil {
+		em.reproject(event)
+		out = append(out, em.checkOver(event.Time)...)
+	}
+	return out, err
+}
 
-	em.lastTime = event.Time
+func (em *monitor) digest(event *model.Event) ([]*model.Event, error) {
+	var out []*model.Event
 	cId := event.CompetitorID
 	comp := em.service.Get(cId)
+	if comp == nil && event.EventID != model.EventRegister && event.EventID != model.EventConfigChanged {
+		return out, fmt.Errorf("event %d of competitor %d: %w", event.EventID, cId, ErrUnknownCompetitor)
+	}
+	if comp != nil && comp.Status == model.Pulled && isCourseEvent(event.EventID) {
+		return out, nil // the competitor may not know yet
+	}
 	switch event.EventID {
 	case model.EventRegister:
 		em.service.Register(cId, em.conf)
+		if name, ok := event.ExtraParams.(string); ok {
+			em.service.Get(cId).Name = name
+		}
 	case model.EventStartTimeSet:
 		comp.PlannedStartTime = event.ExtraParams.(time.Time)
+		em.scheduleStart(comp) // the start time may be redrawn
 	case model.EventOnStartLine:
 		comp.Arrived = true
 	case model.EventStarted:
@@ -58,96 +96,202 @@ func (em *monitor) DigestEvent(event *model.Event) (*model.Event, error) {
 		comp.Status = model.Started
 		comp.StartTime = event.Time
 		comp.LapStartTime = comp.PlannedStartTime
+		for i, d := range em.conf.CutOffs {
+			em.schedule(timer{at: comp.PlannedStartTime.Add(d), kind: timerCutOff, competitorID: cId, lap: i + 1})
+		}
 	case model.EventOnRange:
 		if event.ExtraParams.(int) != comp.FiringLines+1 {
 			return nil, fmt.Errorf("competitor %d is on range %d, not %d", cId, event.ExtraParams.(int), comp.FiringLines+1)
 		}
+		if line := em.conf.FiringLineOfLap(len(comp.Laps)); em.conf.Course != nil && line != event.ExtraParams.(int) {
+			return nil, fmt.Errorf("competitor %d is on range %d on lap %d, the course has firing line %d there",
+				cId, event.ExtraParams.(int), len(comp.Laps)+1, line)
+		}
 		comp.IsFiring = true
+		comp.RangeStartTime = event.Time
+		comp.RangeStartHits = comp.Hits
 	case model.EventTargetHit:
 		if !comp.IsFiring {
 			return nil, fmt.Errorf("competitor %d is not firing", cId)
 		}
+		if target, targets := event.ExtraParams.(int), em.conf.TargetsOnLine(comp.FiringLines+1); target < 1 || target > targets {
+			return nil, fmt.Errorf("competitor %d hit target %d, the firing line has %d targets", cId, target, targets)
+		}
 		comp.Hits += 1
 	case model.EventLeftRange:
+		if comp.IsFiring {
+			comp.RangeTime += event.Time.Sub(comp.RangeStartTime)
+		}
 		comp.FiringLines += 1
 		comp.IsFiring = false
+		misses := em.conf.TargetsOnLine(comp.FiringLines) - (comp.Hits - comp.RangeStartHits)
+		comp.Misses = append(comp.Misses, misses)
+		comp.PenaltyOwed += misses
 	case model.EventEnteredPenalty:
 		comp.PenaltyStartTime = event.Time
+		comp.PenaltyLoops = 0
+		if comp.PenaltyOwed == 0 {
+			out = append(out, em.penaltyWarn(comp, event.Time, "entered the penalty laps without misses"))
+		}
+	case model.EventPenaltyLoop:
+		if comp.PenaltyStartTime.IsZero() {
+			return nil, fmt.Errorf("competitor %d completed a penalty loop without entering the penalty laps", cId)
+		}
+		comp.PenaltyLoops += 1
 	case model.EventLeftPenalty:
 		if comp.PenaltyStartTime.IsZero() {
 			return nil, fmt.Errorf("competitor %d left penalty area without entering it", cId)
 		}
-		comp.PenaltyLaps += event.Time.Sub(comp.PenaltyStartTime)
+		spent := event.Time.Sub(comp.PenaltyStartTime)
+		comp.PenaltyLaps += spent
 		comp.PenaltyStartTime = time.Time{}
+		if warn := em.checkPenaltyLaps(comp, spent, event.Time); warn != nil {
+			out = append(out, warn)
+		}
 
 	case model.EventLapCompleted: // includes penalty laps and shooting
 		if len(comp.Laps) == em.conf.Laps {
 			return nil, fmt.Errorf("competitor %d has already finished", cId)
 		}
 
+		if comp.PenaltyOwed > 0 { // the penalty laps were not entered at all
+			out = append(out, em.skipPenaltyLoops(comp, comp.PenaltyOwed, event.Time))
+		}
+
 		lapTime := event.Time.Sub(comp.LapStartTime)
 		comp.LapStartTime = event.Time // Finish time
 		comp.Laps = append(comp.Laps, lapTime)
+		comp.LapOffCourse = comp.RangeTime + comp.PenaltyLaps
+		comp.LapFiringLines = comp.FiringLines
+		if em.conf.PullLapped {
+			out = append(out, em.pullLapped(comp, event.Time)...)
+		}
 
 		if len(comp.Laps) == em.conf.Laps {
 			comp.Status = model.Finished
-			return &model.Event{
+			return append(out, &model.Event{
 				EventType:    model.OutgoingEvent,
 				EventID:      model.EventFinished,
 				CompetitorID: cId,
 				Time:         event.Time,
-			}, nil
+			}), nil
 		}
 	case model.EventCannotContinue:
 		comp.Status = model.NotFinished
+	case model.EventTimePenalty:
+		comp.TimePenalty += event.ExtraParams.(model.JuryDecision).Penalty
+	case model.EventJuryDisqualified:
+		if comp.Disqualified {
+			return nil, fmt.Errorf("competitor %d is already disqualified", cId)
+		}
+		reason := event.ExtraParams.(model.JuryDecision).Reason
+		comp.Disqualified = true
+		comp.DSQReason = reason
+		return append(out, &model.Event{
+			EventType:    model.OutgoingEvent,
+			EventID:      model.EventDisqualified,
+			CompetitorID: cId,
+			Time:         event.Time,
+			ExtraParams:  reason,
+		}), nil
+	case model.EventConfigChanged:
+		next := event.ExtraParams.(*config.Config)
+		if err := em.CheckConfig(next); err != nil {
+			return nil, fmt.Errorf("config change rejected: %w", err)
+		}
+		*em.conf = *next // the competitors share the config
+		for _, c := range em.service.GetAllMap() {
+			if c.Status == model.NotStarted && !c.PlannedStartTime.IsZero() {
+				em.scheduleStart(c) // the start delta or the reminder may have changed
+			}
+		}
+	case model.EventReinstated:
+		if !comp.Disqualified {
+			return nil, fmt.Errorf("competitor %d is not disqualified", cId)
+		}
+		comp.Disqualified = false
+		comp.DSQReason = model.ReasonNone
+		if comp.Status == model.NotStarted && !comp.PlannedStartTime.IsZero() {
+			em.scheduleStart(comp) // the start is checked against its window again
+		}
 	}
 
-	if id := em.findLate(); id != 0 {
-		return em.disqualify(id), nil
-	}
+	return out, nil
+}
 
-	return nil, nil
+func (em *monitor) Config() *config.Config {
+	return em.conf
+}
+
+// Snapshot copies the monitor with its config, the copy goes on independently
+func (em *monitor) Snapshot() EventMonitor {
+	conf := *em.conf
+	return &monitor{
+		lastTime:   em.lastTime,
+		timers:     slices.Clone(em.timers),
+		results:    em.results,
+		officialAt: em.officialAt,
+		conf:       &conf,
+		service:    em.service.Clone(&conf),
+	}
 }
 
 func (em *monitor) GetReport() []*model.Competitor {
 	return em.service.GetAll()
 }
 
+// Disqualified closes the start windows of all competitors who have not started yet,
+// it is called when no more events are expected
 func (em *monitor) Disqualified() []*model.Event {
-	events := make([]*model.Event, len(em.disqualified))
-	for i, id := range em.disqualified {
-		events[i] = em.disqualify(id)
+	var events []*model.Event
+	over := em.lastTime
+	var rest timerQueue // the cut-offs and the protest window go on
+	for em.timers.Len() > 0 {
+		t := heap.Pop(&em.timers).(timer)
+		if t.kind != timerStartWindow {
+			rest = append(rest, t)
+		} else if e := em.fire(t); e != nil {
+			events = append(events, e)
+			over = t.at
+		}
 	}
-	return events
+	em.timers = rest // popped in order, it is a heap
+	return append(events, em.checkOver(over)...)
 }
 
-func (em *monitor) disqualify(id int) *model.Event {
-	return &model.Event{
-		EventType:    model.OutgoingEvent,
-		EventID:      model.EventDisqualified,
-		CompetitorID: id,
-		Time:         em.lastTime,
+// Advance moves the race clock to the moment now and returns the outgoing events
+// scheduled before it, each stamped with its scheduled time
+func (em *monitor) Advance(now time.Time) []*model.Event {
+	if em.lastTime.IsZero() || now.After(em.lastTime) { // the time-only times are before the zero time
+		em.lastTime = now
 	}
+	return em.fireTimers(now)
 }
 
-func (em *monitor) findLate() int {
-	for id, comp := range em.service.GetAllMap() {
-		if comp.Disqualified {
-			continue
-		}
-		if comp.Status == model.NotStarted {
-			if em.lastTime.Sub(comp.PlannedStartTime) > em.delta {
-				comp.Disqualified = true
-				em.disqualified = append(em.disqualified, id)
-			}
-		}
+func (em *monitor) startWindowClose(comp *model.Competitor) time.Time {
+	return comp.PlannedStartTime.Add(em.conf.StartDelta)
+}
+
+// CheckConfig reports the changes of the next config unsafe at this point of the race
+func (em *monitor) CheckConfig(next *config.Config) error {
+	state := config.RaceState{Events: !em.lastTime.IsZero()}
+	for _, comp := range em.service.GetAllMap() {
+		state.Started = state.Started || comp.Status != model.NotStarted
+		state.Fired = state.Fired || comp.IsFiring || comp.FiringLines > 0
+		state.Finished = state.Finished || comp.Status == model.Finished
 	}
+	return em.conf.CheckUpdate(next, state)
+}
 
-	if len(em.disqualified) > 0 {
-		n := len(em.disqualified) - 1
-		last := em.disqualified[n]
-		em.disqualified = em.disqualified[:n]
-		return last
+// checkInput rejects the event times finer than the input precision of the race
+func (em *monitor) checkInput(event *model.Event) error {
+	f := model.FormatOf(em.conf)
+	err := f.CheckInput(event.Time)
+	if t, ok := event.ExtraParams.(time.Time); ok && err == nil {
+		err = f.CheckInput(t)
+	}
+	if err != nil {
+		return fmt.Errorf("event %d of competitor %d: %w", event.EventID, event.CompetitorID, err)
 	}
-	return 0
+	return nil
 }
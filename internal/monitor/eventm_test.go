@@ -0,0 +1,462 @@
+package monitor_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func tm(tm string) time.Time {
+	t, err := time.Parse(model.TimeLayout, tm)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// sprint is a one lap race with one firing line, the tests adjust the rest
+func sprint() *config.Config {
+	return &config.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Start:       tm("10:00:00.000"),
+		StartDelta:  30 * time.Second,
+	}
+}
+
+// digest feeds the event lines to the monitor and renders the outgoing events
+func digest(t *testing.T, m monitor.EventMonitor, lines ...string) []string {
+	t.Helper()
+	var out []string
+	for _, line := range lines {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		events, err := m.DigestEvent(event)
+		assert.NoError(t, err, line)
+		out = append(out, render(events)...)
+	}
+	return out
+}
+
+func render(events []*model.Event) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, e.String())
+	}
+	return out
+}
+
+func TestLateStartDisqualification(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = time.Hour
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 3",
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 3 10:01:00.000",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:00.000",
+		"[10:30:00.000] 3 1",
+	)
+	assert.Equal(t, []string{
+		"[10:00:30.000] The competitor(1) is disqualified",
+		"[10:00:30.000] The competitor(2) is disqualified",
+		"[10:01:30.000] The competitor(3) is disqualified",
+		"[10:01:30.000] The results are unofficial, the protests are accepted until 11:01:30.000",
+	}, out)
+	assert.Empty(t, m.Disqualified())
+}
+
+func TestStartReminder(t *testing.T) {
+	conf := sprint()
+	conf.StartReminder = time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:59:30.000] 3 2",
+	)
+	out = append(out, render(m.Advance(tm("10:00:40.000")))...)
+	assert.Equal(t, []string{
+		"[09:59:00.000] The competitor(1) is not on the start line, the start is at 10:00:00.000",
+		"[10:00:30.000] The competitor(1) is disqualified",
+	}, out)
+}
+
+func TestCutOffAndLapped(t *testing.T) {
+	conf := sprint()
+	conf.Laps, conf.FiringLines = 3, 0
+	conf.CutOffs = []time.Duration{15 * time.Minute}
+	conf.PullLapped = true
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:00:00.000] 1 3",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:00.000",
+		"[09:10:00.000] 2 3 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[09:59:00.000] 3 2",
+		"[09:59:00.000] 3 3",
+		"[10:00:00.000] 4 1",
+		"[10:00:00.000] 4 2",
+		"[10:00:00.000] 4 3",
+		"[10:08:00.000] 10 1",
+		"[10:14:00.000] 10 2",
+		"[10:16:00.000] 10 1",
+		"[10:24:00.000] 10 1",
+		"[10:25:00.000] 10 2",
+	)
+	assert.Equal(t, []string{
+		"[10:15:00.000] The competitor(3) is pulled from the race: cut-off time of lap 1",
+		"[10:24:00.000] The competitor(2) is pulled from the race: lapped",
+		"[10:24:00.000] The competitor(1) has finished",
+		"[10:24:00.000] The results are unofficial, the protests are accepted until 10:39:00.000",
+	}, out)
+
+	report := m.GetReport()
+	assert.Equal(t, []int{1, 2, 3}, []int{report[0].ID, report[1].ID, report[2].ID})
+	assert.Equal(t, model.Pulled, report[1].Status)
+}
+
+func TestLappedIntervalStart(t *testing.T) {
+	conf := sprint()
+	conf.Laps, conf.FiringLines = 3, 0
+	conf.PullLapped = true
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:00:00.000] 1 3",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:20:00.000",
+		"[09:10:00.000] 2 3 10:01:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:00.000] 4 1",
+		"[10:00:30.000] 3 3",
+		"[10:01:00.000] 4 3",
+		"[10:08:00.000] 10 1",
+		"[10:16:00.000] 10 1",
+		"[10:19:30.000] 3 2",
+		"[10:20:00.000] 4 2",
+		"[10:24:00.000] 10 1", // the second one has started after two laps of the first one
+	)
+	assert.Equal(t, []string{
+		"[10:16:00.000] The competitor(3) is pulled from the race: lapped",
+		"[10:24:00.000] The competitor(1) has finished",
+	}, out)
+
+	status := make(map[int]model.CompetitorStatus)
+	for _, c := range m.GetReport() {
+		status[c.ID] = c.Status
+	}
+	assert.Equal(t, map[int]model.CompetitorStatus{1: model.Finished, 2: model.Started, 3: model.Pulled}, status)
+}
+
+func TestConfigChange(t *testing.T) {
+	conf := sprint()
+	conf.FiringLines = 0
+	m := monitor.NewEventMonitor(conf)
+	digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:00.000] 4 1",
+	)
+
+	next := *conf
+	next.PenaltyLen = 200
+	assert.NoError(t, m.CheckConfig(&next))
+	next.StartDelta = time.Minute
+	assert.Error(t, m.CheckConfig(&next)) // after the first start
+
+	digest(t, m, `[10:05:00.000] 16 0 {"laps": 2, "lapLen": 3000, "penaltyLen": 200, "firingLines": 0, "start": "10:00:00", "startDelta": "00:00:30"}`)
+	assert.Equal(t, 2, conf.Laps)
+	assert.Equal(t, 200, conf.PenaltyLen)
+
+	assert.Empty(t, digest(t, m, "[10:10:00.000] 10 1")) // one more lap to go
+}
+
+func TestStartDeltaChange(t *testing.T) {
+	conf := sprint()
+	conf.FiringLines = 0
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:01:00.000",
+		`[09:59:30.000] 16 0 {"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 0, "start": "10:00:00", "startDelta": "00:01:00", "startReminder": "00:01:00"}`,
+		"[10:00:50.000] 3 1",
+		"[10:00:55.000] 4 1", // late by the old start delta
+	)
+	out = append(out, render(m.Advance(tm("10:02:30.000")))...)
+	assert.Equal(t, []string{
+		"[09:59:30.000] The competitor(1) is not on the start line, the start is at 10:00:00.000", // the reminder time has passed
+		"[10:00:00.000] The competitor(2) is not on the start line, the start is at 10:01:00.000",
+		"[10:02:00.000] The competitor(2) is disqualified",
+	}, out)
+}
+
+func TestProjection(t *testing.T) {
+	conf := sprint()
+	conf.Laps, conf.FiringLines = 3, 2
+	m := monitor.NewEventMonitor(conf)
+	digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:00.000] 4 1",
+		"[10:09:00.000] 5 1 1",
+		"[10:09:05.000] 6 1 1",
+		"[10:09:10.000] 6 1 2",
+		"[10:09:15.000] 6 1 3",
+		"[10:09:20.000] 6 1 4",
+		"[10:09:30.000] 7 1",
+		"[10:09:40.000] 8 1",
+		"[10:10:10.000] 9 1",
+	)
+	comp := m.GetReport()[0]
+	assert.Zero(t, comp.Projected, "no lap completed")
+
+	digest(t, m, "[10:10:30.000] 10 1")
+	// 570s of skiing on 3000m, 6000m left, 30s on the range and 1 of 5 missed with 30s a loop on the last line
+	assert.Equal(t, 30*time.Minute+30*time.Second, comp.Projected)
+}
+
+func TestResultsLifecycle(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:10:00.000] 10 1",
+	)
+	status, officialAt := m.ResultsStatus()
+	assert.Equal(t, model.Unofficial, status)
+	assert.Equal(t, tm("10:25:00.000"), officialAt)
+
+	out = append(out, digest(t, m, "[10:20:00.000] 13 1 US")...) // the jury acts in the protest window
+
+	event, _ := model.ParseEvent("[10:30:00.000] 14 1")
+	events, err := m.DigestEvent(event)
+	assert.ErrorIs(t, err, monitor.ErrResultsOfficial)
+	out = append(out, render(events)...)
+
+	assert.Equal(t, []string{
+		"[10:01:00.000] The competitor(2) is disqualified",
+		"[10:10:00.000] The competitor(1) has finished",
+		"[10:10:00.000] The results are unofficial, the protests are accepted until 10:25:00.000",
+		"[10:20:00.000] The competitor(1) is disqualified: unsportsmanlike conduct",
+		"[10:25:00.000] The results are official",
+	}, out)
+	status, _ = m.ResultsStatus()
+	assert.Equal(t, model.Official, status)
+	assert.True(t, m.GetReport()[0].Disqualified, "the jury decision stands, the reinstatement is rejected")
+}
+
+func TestResultsReopened(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:59:00.000] 3 1",
+		"[09:59:30.000] 3 2",
+		"[10:00:01.000] 4 1",
+		"[10:00:31.000] 4 2",
+		"[10:05:00.000] 13 2 CC",
+		"[10:10:00.000] 10 1",
+		"[10:15:00.000] 14 2", // back in the race
+	)
+	status, officialAt := m.ResultsStatus()
+	assert.Equal(t, model.Provisional, status)
+	assert.True(t, officialAt.IsZero())
+
+	out = append(out, digest(t, m, "[10:40:00.000] 10 2")...)
+	out = append(out, render(m.Advance(tm("11:00:00.000")))...)
+	assert.Equal(t, []string{
+		"[10:05:00.000] The competitor(2) is disqualified: course shortcut",
+		"[10:10:00.000] The competitor(1) has finished",
+		"[10:10:00.000] The results are unofficial, the protests are accepted until 10:25:00.000",
+		"[10:40:00.000] The competitor(2) has finished",
+		"[10:40:00.000] The results are unofficial, the protests are accepted until 10:55:00.000",
+		"[10:55:00.000] The results are official",
+	}, out)
+}
+
+func TestOfficialAfterInput(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:10:00.000] 10 1",
+	)
+
+	assert.Empty(t, m.Disqualified())
+	assert.Equal(t, []string{"[10:25:00.000] The results are official"}, render(m.Advance(tm("10:30:00.000"))))
+	status, _ := m.ResultsStatus()
+	assert.Equal(t, model.Official, status)
+}
+
+func TestNoProtestWindow(t *testing.T) {
+	m := monitor.NewEventMonitor(sprint())
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:10:00.000] 10 1",
+	)
+	assert.Equal(t, []string{
+		"[10:10:00.000] The competitor(1) has finished",
+		"[10:10:00.000] The results are unofficial, the protests are accepted until 10:10:00.000",
+		"[10:10:00.000] The results are official",
+	}, out)
+	status, officialAt := m.ResultsStatus()
+	assert.Equal(t, model.Official, status)
+	assert.Equal(t, tm("10:10:00.000"), officialAt)
+
+	event, _ := model.ParseEvent("[10:11:00.000] 13 1 US")
+	_, err := m.DigestEvent(event)
+	assert.ErrorIs(t, err, monitor.ErrResultsOfficial)
+}
+
+func TestJuryDecisions(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 2",
+		"[09:00:00.000] 1 3",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:10:00.000] 2 3 10:01:00.000",
+		"[09:59:00.000] 13 2 EQ",
+		"[09:59:10.000] 3 1",
+		"[09:59:30.000] 14 2", // still has to start in the interval
+		"[10:00:05.000] 4 1",
+		"[10:02:00.000] 14 3", // the start interval is over
+		"[10:05:00.000] 12 1 00:01:00 MP",
+		"[10:06:00.000] 13 1 CC",
+		"[10:07:00.000] 14 1",
+		"[10:10:00.000] 10 1",
+	)
+	assert.Equal(t, []string{
+		"[09:59:00.000] The competitor(2) is disqualified: equipment violation",
+		"[10:01:00.000] The competitor(2) is disqualified",
+		"[10:01:30.000] The competitor(3) is disqualified",
+		"[10:02:00.000] The competitor(3) is disqualified",
+		"[10:06:00.000] The competitor(1) is disqualified: course shortcut",
+		"[10:06:00.000] The results are unofficial, the protests are accepted until 10:21:00.000",
+		"[10:10:00.000] The competitor(1) has finished",
+		"[10:10:00.000] The results are unofficial, the protests are accepted until 10:25:00.000",
+	}, out)
+
+	report := m.GetReport()
+	assert.False(t, report[0].Disqualified)
+	assert.Equal(t, model.ReasonNone, report[0].DSQReason)
+	assert.Equal(t, time.Minute, report[0].TimePenalty)
+	assert.Equal(t, 11*time.Minute, report[0].TotalTime())
+	for _, c := range report[1:] {
+		assert.True(t, c.Disqualified)
+		assert.Equal(t, model.ReasonLateStart, c.DSQReason, "competitor %d", c.ID)
+	}
+}
+
+func TestJuryDisqualifiedTwice(t *testing.T) {
+	conf := sprint()
+	conf.ProtestWindow = 15 * time.Minute
+	m := monitor.NewEventMonitor(conf)
+	out := digest(t, m,
+		"[09:00:00.000] 1 1",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:01.000] 4 1",
+		"[10:05:00.000] 13 1 US",
+	)
+
+	event, _ := model.ParseEvent("[10:06:00.000] 13 1 CC")
+	events, err := m.DigestEvent(event)
+	assert.Error(t, err)
+	assert.Empty(t, events)
+	assert.Len(t, out, 2) // disqualified and unofficial
+	assert.Equal(t, model.ReasonUnsporting, m.GetReport()[0].DSQReason, "the first decision stands")
+}
+
+func TestSkippedPenaltyLoops(t *testing.T) {
+	conf := sprint()
+	conf.SkippedLoopPenalty = time.Minute
+	m := monitor.NewEventMonitor(conf)
+	var lines []string
+	for id := 1; id <= 4; id++ {
+		lines = append(lines,
+			fmt.Sprintf("[09:00:00.000] 1 %d", id),
+			fmt.Sprintf("[09:10:00.000] 2 %d 10:00:00.000", id),
+			fmt.Sprintf("[09:59:00.000] 3 %d", id),
+			fmt.Sprintf("[10:00:00.000] 4 %d", id),
+			fmt.Sprintf("[10:05:00.000] 5 %d 1", id),
+			fmt.Sprintf("[10:05:10.000] 6 %d 1", id),
+			fmt.Sprintf("[10:05:20.000] 6 %d 2", id),
+			fmt.Sprintf("[10:05:30.000] 6 %d 3", id),
+			fmt.Sprintf("[10:05:40.000] 7 %d", id), // 2 misses
+		)
+	}
+	out := digest(t, m, lines...)
+	out = append(out, digest(t, m,
+		"[10:06:00.000] 8 2",
+		"[10:06:00.000] 8 3",
+		"[10:06:00.000] 8 4",
+		"[10:06:15.000] 15 2",
+		"[10:06:20.000] 9 3", // 200m at most in 20s with the default 10 m/s
+		"[10:06:30.000] 9 2", // one loop of two
+		"[10:06:30.000] 9 4", // two loops in 30s
+		"[10:10:00.000] 10 1",
+		"[10:10:00.000] 10 2",
+		"[10:10:00.000] 10 3",
+		"[10:10:00.000] 10 4",
+	)...)
+	assert.Equal(t, []string{
+		"[10:06:20.000] The competitor(3) penalty laps warning: skipped 1 penalty loop(s), time penalty 00:01:00.000",
+		"[10:06:30.000] The competitor(2) penalty laps warning: skipped 1 penalty loop(s), time penalty 00:01:00.000",
+		"[10:10:00.000] The competitor(1) penalty laps warning: skipped 2 penalty loop(s), time penalty 00:02:00.000",
+		"[10:10:00.000] The competitor(1) has finished",
+		"[10:10:00.000] The competitor(2) has finished",
+		"[10:10:00.000] The competitor(3) has finished",
+		"[10:10:00.000] The competitor(4) has finished",
+		"[10:10:00.000] The results are unofficial, the protests are accepted until 10:10:00.000",
+		"[10:10:00.000] The results are official",
+	}, out)
+
+	report := m.GetReport()
+	penalties := map[int]time.Duration{1: 2 * time.Minute, 2: time.Minute, 3: time.Minute, 4: 0}
+	for _, c := range report {
+		assert.Equal(t, penalties[c.ID], c.TimePenalty, "competitor %d", c.ID)
+		assert.Equal(t, 10*time.Minute+penalties[c.ID], c.TotalTime(), "competitor %d", c.ID)
+	}
+}
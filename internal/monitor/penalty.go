@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// checkPenaltyLaps settles the owed penalty loops when the competitor leaves the penalty laps.
+// Explicit penalty loop events are trusted, otherwise the number of loops is estimated
+// from the time spent and the plausible penalty speed range.
+func (em *monitor) checkPenaltyLaps(comp *model.Competitor, spent time.Duration, at time.Time) *model.Event {
+	owed := comp.PenaltyOwed
+	comp.PenaltyOwed = 0
+	if owed == 0 || em.conf.PenaltyLen <= 0 {
+		return nil
+	}
+
+	if comp.PenaltyLoops > 0 {
+		if comp.PenaltyLoops < owed {
+			return em.skipPenaltyLoops(comp, owed-comp.PenaltyLoops, at)
+		}
+		return nil
+	}
+
+	lo, hi := em.conf.PenaltySpeedRange()
+	length := float64(em.conf.PenaltyLen)
+	maxLoops := int(math.Floor(spent.Seconds() * hi / length))
+	if maxLoops < owed {
+		return em.skipPenaltyLoops(comp, owed-maxLoops, at)
+	}
+	if minLoops := int(math.Floor(spent.Seconds() * lo / length)); lo > 0 && minLoops > owed {
+		return em.penaltyWarn(comp, at, fmt.Sprintf("too long in the penalty laps for %d loop(s)", owed))
+	}
+	return nil
+}
+
+func (em *monitor) skipPenaltyLoops(comp *model.Competitor, loops int, at time.Time) *model.Event {
+	comp.PenaltyOwed = 0
+	comp.SkippedLoops += loops
+	msg := fmt.Sprintf("skipped %d penalty loop(s)", loops)
+	if em.conf.SkippedLoopPenalty > 0 {
+		penalty := time.Duration(loops) * em.conf.SkippedLoopPenalty
+		comp.TimePenalty += penalty
+		msg += ", time penalty " + model.FormatOf(em.conf).FormatDuration(penalty)
+	}
+	return em.penaltyWarn(comp, at, msg)
+}
+
+func (em *monitor) penaltyWarn(comp *model.Competitor, at time.Time, msg string) *model.Event {
+	return &model.Event{
+		EventType:    model.OutgoingEvent,
+		EventID:      model.EventPenaltyWarn,
+		CompetitorID: comp.ID,
+		Time:         at,
+		ExtraParams:  msg,
+	}
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// the projection assumptions before the competitor has shot
+const (
+	defaultRangeTime = 30 * time.Second
+	defaultMissRate  = 0.2
+)
+
+// reproject updates the projections affected by the digested event
+func (em *monitor) reproject(event *model.Event) {
+	if event.EventID == model.EventConfigChanged {
+		for _, comp := range em.service.GetAllMap() {
+			em.project(comp)
+		}
+	} else if comp := em.service.Get(event.CompetitorID); comp != nil {
+		em.project(comp)
+	}
+}
+
+// project estimates the total time of the running competitor: the skiing pace of the completed laps
+// over the rest of the course, the mean range time and the miss rate so far on the remaining firing lines
+func (em *monitor) project(comp *model.Competitor) {
+	comp.Projected = 0
+	if comp.Status != model.Started || comp.Disqualified || len(comp.Laps) == 0 || len(comp.Laps) >= em.conf.Laps {
+		return
+	}
+	done, left := 0, 0
+	for i := range em.conf.Laps {
+		if i < len(comp.Laps) {
+			done += em.conf.LapLength(i)
+		} else {
+			left += em.conf.LapLength(i)
+		}
+	}
+	ski := comp.TimeFromPlannedStart() - comp.LapOffCourse
+	if done == 0 || ski <= 0 {
+		return
+	}
+	pace := float64(ski) / float64(done) // per meter
+	projected := comp.TotalTime() + time.Duration(pace*float64(left))
+
+	hits := comp.PassedHits()
+	rangeTime, missRate := defaultRangeTime, defaultMissRate
+	if comp.FiringLines > 0 {
+		rangeTime = comp.RangeTime / time.Duration(comp.FiringLines)
+		missRate = float64(comp.Shots()-hits) / float64(comp.Shots())
+	}
+	loop := time.Duration(pace * float64(em.conf.PenaltyLen))
+	if misses := comp.Shots() - hits; misses > 0 && comp.PenaltyLaps > 0 {
+		loop = comp.PenaltyLaps / time.Duration(misses)
+	}
+	for line := comp.LapFiringLines + 1; line <= em.conf.FiringLines; line++ {
+		projected += rangeTime + time.Duration(missRate*float64(em.conf.TargetsOnLine(line))*float64(loop))
+	}
+	comp.Projected = projected
+}
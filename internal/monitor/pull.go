@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+func isCourseEvent(eventID int) bool {
+	return eventID >= model.EventOnStartLine && eventID <= model.EventCannotContinue || eventID == model.EventPenaltyLoop
+}
+
+// pullLapped pulls the running competitors a full lap behind the one who has just completed a lap.
+// The competitor may be anywhere on its current lap, so it is surely lapped two laps behind on course
+// at this time, not counting the laps of the leader before the start of the competitor.
+func (em *monitor) pullLapped(leader *model.Competitor, at time.Time) []*model.Event {
+	var lapped []*model.Competitor
+	for _, comp := range em.service.GetAllMap() {
+		if comp.Status == model.Started && !comp.Disqualified &&
+			len(comp.Laps)+lapsBy(leader, comp.PlannedStartTime) <= len(leader.Laps)-2 {
+			lapped = append(lapped, comp)
+		}
+	}
+	sort.Slice(lapped, func(i, j int) bool { return lapped[i].ID < lapped[j].ID })
+
+	events := make([]*model.Event, len(lapped))
+	for i, comp := range lapped {
+		events[i] = em.pull(comp, at, "lapped")
+	}
+	return events
+}
+
+// lapsBy counts the laps the competitor has completed by the time, the laps are timed from the planned start
+func lapsBy(comp *model.Competitor, t time.Time) int {
+	end := comp.PlannedStartTime
+	for i, d := range comp.Laps {
+		if end = end.Add(d); end.After(t) {
+			return i
+		}
+	}
+	return len(comp.Laps)
+}
+
+func (em *monitor) pull(comp *model.Competitor, at time.Time, reason string) *model.Event {
+	comp.Status = model.Pulled
+	comp.IsFiring = false
+	comp.PenaltyStartTime = time.Time{}
+	return &model.Event{
+		EventType:    model.OutgoingEvent,
+		EventID:      model.EventPulled,
+		CompetitorID: comp.ID,
+		Time:         at,
+		ExtraParams:  reason,
+	}
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"container/heap"
+	"slices"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+func (em *monitor) ResultsStatus() (model.ResultsStatus, time.Time) {
+	return em.results, em.officialAt
+}
+
+// checkOver makes the results unofficial when no competitor is left in the race,
+// the results become official after the protest window, at once without it.
+// The unofficial results are provisional again when a competitor is back in the race.
+func (em *monitor) checkOver(now time.Time) []*model.Event {
+	if em.results == model.Unofficial && !em.over() {
+		em.results = model.Provisional
+		em.officialAt = time.Time{}
+		em.timers = slices.DeleteFunc(em.timers, func(t timer) bool { return t.kind == timerOfficial })
+		heap.Init(&em.timers)
+		return nil
+	}
+	if em.results != model.Provisional || !em.over() {
+		return nil
+	}
+	em.results = model.Unofficial
+	em.officialAt = now.Add(em.conf.ProtestWindow)
+	unofficial := &model.Event{
+		EventType:   model.OutgoingEvent,
+		EventID:     model.EventUnofficial,
+		Time:        now,
+		ExtraParams: em.officialAt,
+	}
+	if em.conf.ProtestWindow == 0 { // no protests are accepted
+		return []*model.Event{unofficial, em.official(now)}
+	}
+	em.schedule(timer{at: em.officialAt, kind: timerOfficial})
+	return []*model.Event{unofficial}
+}
+
+// over reports whether every competitor has finished, is out of the race or disqualified
+func (em *monitor) over() bool {
+	competitors := em.service.GetAllMap()
+	if len(competitors) == 0 {
+		return false
+	}
+	for _, c := range competitors {
+		if !c.Disqualified && (c.Status == model.NotStarted || c.Status == model.Started) {
+			return false
+		}
+	}
+	return true
+}
+
+// official freezes the results at the end of the protest window
+func (em *monitor) official(at time.Time) *model.Event {
+	if em.results != model.Unofficial || !at.Equal(em.officialAt) {
+		return nil
+	}
+	em.results = model.Official
+	return &model.Event{
+		EventType: model.OutgoingEvent,
+		EventID:   model.EventOfficial,
+		Time:      at,
+	}
+}
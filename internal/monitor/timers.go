@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+type timerKind int
+
+const (
+	timerStartReminder timerKind = iota
+	timerStartWindow
+	timerCutOff
+	timerOfficial
+)
+
+// timer is an outgoing event scheduled at the race time
+type timer struct {
+	at           time.Time
+	kind         timerKind
+	competitorID int
+	lap          int // for the cut-off timers
+}
+
+type timerQueue []timer // min-heap ordered by time, kind and competitor ID
+
+func (q timerQueue) Len() int { return len(q) }
+func (q timerQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	if q[i].kind != q[j].kind {
+		return q[i].kind < q[j].kind
+	}
+	return q[i].competitorID < q[j].competitorID
+}
+func (q timerQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *timerQueue) Push(x any)   { *q = append(*q, x.(timer)) }
+func (q *timerQueue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	*q = old[:n-1]
+	return t
+}
+
+func (em *monitor) schedule(t timer) {
+	heap.Push(&em.timers, t)
+}
+
+// scheduleStart replaces the start reminder and the start window timers of the competitor
+// by the ones of the current config, the deadlines already passed are due at once
+func (em *monitor) scheduleStart(comp *model.Competitor) {
+	em.timers = slices.DeleteFunc(em.timers, func(t timer) bool {
+		return t.competitorID == comp.ID && (t.kind == timerStartReminder || t.kind == timerStartWindow)
+	})
+	heap.Init(&em.timers)
+	em.schedule(timer{at: em.notBefore(em.startWindowClose(comp)), kind: timerStartWindow, competitorID: comp.ID})
+	if at := em.notBefore(comp.PlannedStartTime.Add(-em.conf.StartReminder)); em.conf.StartReminder > 0 && at.Before(comp.PlannedStartTime) {
+		em.schedule(timer{at: at, kind: timerStartReminder, competitorID: comp.ID})
+	}
+}
+
+// notBefore moves the time passed by the race clock to the current moment
+func (em *monitor) notBefore(at time.Time) time.Time {
+	if at.Before(em.lastTime) {
+		return em.lastTime
+	}
+	return at
+}
+
+// fireTimers fires the timers due strictly before now
+func (em *monitor) fireTimers(now time.Time) []*model.Event {
+	var events []*model.Event
+	for em.timers.Len() > 0 && em.timers[0].at.Before(now) {
+		t := heap.Pop(&em.timers).(timer)
+		if e := em.fire(t); e != nil {
+			events = append(events, e)
+			events = append(events, em.checkOver(t.at)...)
+		}
+	}
+	return events
+}
+
+// fire produces the scheduled event if it is still actual
+func (em *monitor) fire(t timer) *model.Event {
+	if t.kind == timerOfficial {
+		return em.official(t.at)
+	}
+	comp := em.service.Get(t.competitorID)
+	if comp == nil || comp.Disqualified {
+		return nil
+	}
+
+	switch t.kind {
+	case timerStartReminder:
+		if comp.Status != model.NotStarted || comp.Arrived {
+			return nil
+		}
+		return &model.Event{
+			EventType:    model.OutgoingEvent,
+			EventID:      model.EventStartReminder,
+			CompetitorID: comp.ID,
+			Time:         t.at,
+			ExtraParams:  comp.PlannedStartTime,
+		}
+	case timerStartWindow:
+		if comp.Status != model.NotStarted {
+			return nil
+		}
+		comp.Disqualified = true
+		comp.DSQReason = model.ReasonLateStart
+		return &model.Event{
+			EventType:    model.OutgoingEvent,
+			EventID:      model.EventDisqualified,
+			CompetitorID: comp.ID,
+			Time:         t.at,
+		}
+	case timerCutOff:
+		if comp.Status == model.Started && len(comp.Laps) < t.lap {
+			return em.pull(comp, t.at, fmt.Sprintf("cut-off time of lap %d", t.lap))
+		}
+	}
+	return nil
+}
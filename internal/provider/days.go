@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
+)
+
+// a time going back by more than this is considered as the next day
+const rolloverThreshold = 12 * time.Hour
+
+// dayRoller places time-only events on the race days: on the date of the last dated event
+// and on the next day when the time of day goes back over midnight
+type dayRoller struct {
+	last time.Time
+}
+
+func (r *dayRoller) roll(e *model.Event) {
+	if model.IsTimeOnly(e.Time) && !r.last.IsZero() {
+		e.Time = nextAfter(model.OnDay(e.Time, r.last), r.last)
+	}
+	if t, ok := e.ExtraParams.(time.Time); ok && model.IsTimeOnly(t) { // start time is not before the draw
+		e.ExtraParams = nextAfter(model.OnDay(t, e.Time), e.Time)
+	}
+	r.last = e.Time
+}
+
+func nextAfter(t, last time.Time) time.Time {
+	if last.Sub(t) > rolloverThreshold {
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func normalize(e *model.Event, zone tz.Zone) {
+	e.Time = zone.ToUTC(e.Time)
+	if t, ok := e.ExtraParams.(time.Time); ok {
+		e.ExtraParams = zone.ToUTC(t)
+	}
+}
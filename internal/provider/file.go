@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
 )
 
 func ScanFile(filename string) ([]*model.Event, error) {
@@ -19,6 +20,7 @@ func ScanFile(filename string) ([]*model.Event, error) {
 
 	var events []*model.Event
 	var last *model.Event
+	var days dayRoller
 
 	scanner := bufio.NewScanner(f)
 	scanner.Split(bufio.ScanLines)
@@ -29,11 +31,12 @@ func ScanFile(filename string) ([]*model.Event, error) {
 		if err != nil {
 			return nil, fmt.Errorf("parsing error: %w", err)
 		}
+		days.roll(cur)
 
 		if last != nil && last.Time.After(cur.Time) {
 			return nil, fmt.Errorf("event order error: %s > %s",
-				last.Time.Format(model.TimeLayout),
-				cur.Time.Format(model.TimeLayout))
+				model.TimeFormat{}.FormatTime(last.Time),
+				model.TimeFormat{}.FormatTime(cur.Time))
 		}
 
 		last = cur
@@ -44,6 +47,11 @@ func ScanFile(filename string) ([]*model.Event, error) {
 }
 
 func Scan(ctx context.Context, source io.Reader) (<-chan *model.Event, <-chan error) {
+	return ScanIn(ctx, source, tz.Zone{})
+}
+
+// ScanIn scans the source whose times are in the given time zone, the times are normalized to UTC
+func ScanIn(ctx context.Context, source io.Reader, zone tz.Zone) (<-chan *model.Event, <-chan error) {
 	events := make(chan *model.Event)
 	errs := make(chan error, 1)
 
@@ -51,6 +59,8 @@ func Scan(ctx context.Context, source io.Reader) (<-chan *model.Event, <-chan er
 		defer close(events)
 		defer close(errs)
 		var last *model.Event
+		var days dayRoller
+		format := model.TimeFormat{Venue: zone} // the messages show the times as they are in the source
 		scanner := bufio.NewScanner(source)
 		scanner.Split(bufio.ScanLines)
 
@@ -70,10 +80,12 @@ func Scan(ctx context.Context, source io.Reader) (<-chan *model.Event, <-chan er
 					errs <- fmt.Errorf("parsing error: %w", err)
 					return
 				}
+				days.roll(cur)
+				normalize(cur, zone)
 				if last != nil && last.Time.After(cur.Time) {
 					errs <- fmt.Errorf("event order error: %s > %s",
-						last.Time.Format(model.TimeLayout),
-						cur.Time.Format(model.TimeLayout))
+						format.FormatTime(last.Time),
+						format.FormatTime(cur.Time))
 					return
 				}
 				last = cur
@@ -93,3 +105,23 @@ func Scan(ctx context.Context, source io.Reader) (<-chan *model.Event, <-chan er
 	}()
 	return events, errs
 }
+
+// Skip drops the first n events, e.g. the ones digested before the resumed run
+func Skip(ctx context.Context, events <-chan *model.Event, n int) <-chan *model.Event {
+	out := make(chan *model.Event)
+	go func() {
+		defer close(out)
+		for e := range events {
+			if n > 0 {
+				n--
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- e:
+			}
+		}
+	}()
+	return out
+}
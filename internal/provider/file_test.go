@@ -0,0 +1,56 @@
+package provider_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(input string) ([]*model.Event, error) {
+	events, errs := provider.Scan(context.Background(), strings.NewReader(input))
+	var res []*model.Event
+	for e := range events {
+		res = append(res, e)
+	}
+	err := <-errs
+	return res, err
+}
+
+func TestMidnightRollover(t *testing.T) {
+	events, err := collect(strings.Join([]string{
+		"[23:50:00.000] 1 1",
+		"[23:55:00.000] 2 1 00:05:00.000",
+		"[00:04:00.000] 3 1",
+		"[00:05:01.000] 4 1",
+	}, "\n"))
+	assert.NoError(t, err)
+	assert.Len(t, events, 4)
+
+	nextDay := time.Date(0, 1, 2, 0, 5, 0, 0, time.UTC)
+	assert.Equal(t, nextDay, events[1].ExtraParams)
+	assert.Equal(t, nextDay.Add(time.Second), events[3].Time)
+	assert.Equal(t, "day2", events[3].RaceID())
+	assert.Equal(t, "[00:05:01.000] The competitor(1) has started", events[3].String())
+}
+
+func TestDatedEvents(t *testing.T) {
+	events, err := collect(strings.Join([]string{
+		"[2025-03-01T09:00:00.000] 1 1",
+		"[09:10:00.000] 1 2",
+		"[2025-03-02T09:00:00.000] 3 1",
+	}, "\n"))
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 10, 0, 0, time.UTC), events[1].Time)
+	assert.Equal(t, "2025-03-02", events[2].RaceID())
+}
+
+func TestEventOrder(t *testing.T) {
+	_, err := collect("[09:00:00.000] 1 1\n[08:00:00.000] 1 2")
+	assert.Error(t, err)
+}
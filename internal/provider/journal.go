@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// Journal appends the digested incoming events to a file in the input format.
+// The times are the internal UTC ones, so the journal is replayed with the UTC source time zone.
+type Journal struct {
+	f *os.File
+}
+
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{f: f}, nil
+}
+
+func (j *Journal) Write(event *model.Event) error {
+	if _, err := fmt.Fprintln(j.f, event.Line()); err != nil {
+		return fmt.Errorf("journal: %w", err)
+	}
+	return nil
+}
+
+// Sync flushes the journal to the disk
+func (j *Journal) Sync() error {
+	return j.f.Sync()
+}
+
+// Close flushes the journal to the disk
+func (j *Journal) Close() error {
+	if err := j.f.Sync(); err != nil {
+		j.f.Close()
+		return err
+	}
+	return j.f.Close()
+}
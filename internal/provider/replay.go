@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// ErrSeekBack is returned for the seeks before the replay position, the digested events can not be undone
+var ErrSeekBack = errors.New("can not seek back")
+
+// Replayer paces the scanned events by their times, at the real pace or faster, like they come
+// from the timing system. It can be paused, sped up and moved forward to any moment of the race.
+// It is also the race clock of the replay: it ticks the race time between the events.
+type Replayer struct {
+	mu      sync.Mutex
+	speed   float64
+	paused  bool
+	started bool
+	pos     time.Time // race time at the wall time since
+	since   time.Time
+	changed chan struct{} // wakes the replay on the control changes
+
+	ticks    chan time.Time
+	interval time.Duration
+	now      func() time.Time
+	after    func(time.Duration) <-chan time.Time
+}
+
+// ReplayState is the position and the controls of the replay
+type ReplayState struct {
+	Position time.Time
+	Speed    float64
+	Paused   bool
+}
+
+// NewReplayer creates the replay at speed times the real pace, ticking with the wall interval
+func NewReplayer(speed float64, interval time.Duration) (*Replayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("replay speed must be positive: %g", speed)
+	}
+	return &Replayer{
+		speed:    speed,
+		changed:  make(chan struct{}, 1),
+		ticks:    make(chan time.Time, 1),
+		interval: interval,
+		now:      time.Now,
+		after:    time.After,
+	}, nil
+}
+
+// SetClock replaces the wall clock and the timers of the replay, it is set before the replay starts
+func (r *Replayer) SetClock(now func() time.Time, after func(time.Duration) <-chan time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now, r.after = now, after
+}
+
+// Replay emits the events of the source when the replay reaches their times
+func (r *Replayer) Replay(ctx context.Context, source <-chan *model.Event) <-chan *model.Event {
+	events := make(chan *model.Event)
+	go func() {
+		defer close(events)
+		defer close(r.ticks)
+
+		for next := range source {
+			r.start(next.Time)
+			for {
+				wait, pos := r.until(next.Time)
+				if wait <= 0 {
+					break
+				}
+				r.tick(pos) // after all events before it
+				select {
+				case <-ctx.Done():
+					return
+				case <-r.after(wait):
+				case <-r.changed:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case events <- next:
+			}
+		}
+	}()
+	return events
+}
+
+// start places the replay at the first event unless it was moved before
+func (r *Replayer) start(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started {
+		r.started = true
+		r.pos, r.since = t, r.now()
+	}
+}
+
+// until returns the wall time to wait for the race time t, at most the tick interval, and the race time now
+func (r *Replayer) until(t time.Time) (time.Duration, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pos := r.position()
+	left := t.Sub(pos)
+	if left <= 0 {
+		return 0, pos
+	}
+	if r.paused {
+		return r.interval, pos
+	}
+	return min(time.Duration(float64(left)/r.speed), r.interval), pos
+}
+
+func (r *Replayer) tick(now time.Time) {
+	select {
+	case r.ticks <- now:
+	default: // the previous tick is not consumed yet, it will be superseded
+	}
+}
+
+// position is the race time now, called under the lock
+func (r *Replayer) position() time.Time {
+	if r.paused || !r.started {
+		return r.pos
+	}
+	return r.pos.Add(time.Duration(float64(r.now().Sub(r.since)) * r.speed))
+}
+
+// rebase fixes the position at the wall time now before the controls change, called under the lock
+func (r *Replayer) rebase() {
+	r.pos, r.since = r.position(), r.now()
+}
+
+func (r *Replayer) wake() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Now returns the race time of the replay
+func (r *Replayer) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.position()
+}
+
+// Ticks sends the race time between the events
+func (r *Replayer) Ticks() <-chan time.Time {
+	return r.ticks
+}
+
+func (r *Replayer) State() ReplayState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReplayState{Position: r.position(), Speed: r.speed, Paused: r.paused}
+}
+
+func (r *Replayer) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebase()
+	r.paused = true
+	r.wake()
+}
+
+func (r *Replayer) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebase()
+	r.paused = false
+	r.wake()
+}
+
+// SetSpeed sets the pace of the replay, times the real pace
+func (r *Replayer) SetSpeed(speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("replay speed must be positive: %g", speed)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebase()
+	r.speed = speed
+	r.wake()
+	return nil
+}
+
+// Seek moves the replay forward to the race time t, the events before it are emitted at once
+func (r *Replayer) Seek(t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started && t.Before(r.position()) {
+		return fmt.Errorf("%w, the replay is %s past the time", ErrSeekBack, r.position().Sub(t).Round(time.Millisecond))
+	}
+	r.started = true
+	r.pos, r.since = t, r.now()
+	r.wake()
+	return nil
+}
@@ -0,0 +1,114 @@
+package provider_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+// wallClock is the wall time and the timers moved by the test
+type wallClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits chan time.Duration
+	fire  chan time.Time
+}
+
+func newWallClock() *wallClock {
+	return &wallClock{
+		now:   time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		waits: make(chan time.Duration),
+		fire:  make(chan time.Time),
+	}
+}
+
+func (c *wallClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After blocks the replay until the test takes the wait, the timer fires on the next step
+func (c *wallClock) After(d time.Duration) <-chan time.Time {
+	c.waits <- d
+	return c.fire
+}
+
+// Step moves the wall clock and fires the timer of the replay
+func (c *wallClock) Step(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+	c.fire <- c.Now()
+}
+
+// waiting checks that the replay waits for the next event for the wall time d
+func waiting(t *testing.T, wall *wallClock, d time.Duration) {
+	assert.Equal(t, d, <-wall.waits)
+}
+
+func TestReplay(t *testing.T) {
+	source, _ := provider.Scan(context.Background(), strings.NewReader(strings.Join([]string{
+		"[10:00:00.000] 1 1",
+		"[10:01:00.000] 1 2",
+		"[10:02:00.000] 1 3",
+		"[11:00:00.000] 1 4",
+	}, "\n")))
+	replay, err := provider.NewReplayer(600, time.Second) // a minute in 100ms
+	assert.NoError(t, err)
+	_, err = provider.NewReplayer(0, time.Second)
+	assert.Error(t, err)
+	wall := newWallClock()
+	replay.SetClock(wall.Now, wall.After)
+
+	events := replay.Replay(context.Background(), source)
+	assert.Equal(t, 1, (<-events).CompetitorID)
+	assert.Equal(t, tm("10:00:00.000"), replay.Now())
+	waiting(t, wall, 100*time.Millisecond)
+	wall.Step(100 * time.Millisecond)
+	assert.Equal(t, 2, (<-events).CompetitorID)
+	assert.Equal(t, tm("10:01:00.000"), replay.Now())
+
+	waiting(t, wall, 100*time.Millisecond)
+	replay.Pause()
+	waiting(t, wall, time.Second) // the tick interval while paused
+	wall.Step(time.Second)
+	waiting(t, wall, time.Second)
+	assert.Equal(t, provider.ReplayState{Position: tm("10:01:00.000"), Speed: 600, Paused: true}, replay.State())
+	replay.Resume()
+	waiting(t, wall, 100*time.Millisecond)
+	wall.Step(50 * time.Millisecond)
+	assert.Equal(t, tm("10:01:30.000"), replay.Now())
+	waiting(t, wall, 50*time.Millisecond)
+	wall.Step(50 * time.Millisecond)
+	assert.Equal(t, 3, (<-events).CompetitorID)
+
+	assert.ErrorIs(t, replay.Seek(tm("10:00:30.000")), provider.ErrSeekBack)
+	assert.Error(t, replay.SetSpeed(-1))
+	waiting(t, wall, time.Second) // at most the tick interval
+	assert.NoError(t, replay.Seek(tm("10:59:00.000")))
+	waiting(t, wall, 100*time.Millisecond)
+	assert.NoError(t, replay.SetSpeed(60))
+	waiting(t, wall, time.Second)
+	wall.Step(time.Second)
+	assert.Equal(t, 4, (<-events).CompetitorID)
+	assert.Equal(t, tm("11:00:00.000"), replay.Now())
+	_, ok := <-events
+	assert.False(t, ok)
+	for range replay.Ticks() { // closed at the end
+	}
+}
+
+func tm(tm string) time.Time {
+	t, err := time.Parse(model.TimeLayout, tm)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
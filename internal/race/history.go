@@ -0,0 +1,104 @@
+package race
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/monitor"
+)
+
+// snapshotEvery is the number of the digested events between the snapshots of the race
+const snapshotEvery = 64
+
+type snapshot struct {
+	events  int // digested before the snapshot
+	monitor monitor.EventMonitor
+}
+
+// history keeps the digested events of the race and the snapshots of its monitor,
+// so the race at any moment is replayed from the closest snapshot
+type history struct {
+	events    []*model.Event
+	snapshots []snapshot // the first one is the race before any event
+}
+
+func newHistory(m monitor.EventMonitor) history {
+	return history{snapshots: []snapshot{{0, m.Snapshot()}}}
+}
+
+func (h *history) record(m monitor.EventMonitor, event *model.Event) {
+	h.events = append(h.events, event)
+	if len(h.events)%snapshotEvery == 0 {
+		h.snapshots = append(h.snapshots, snapshot{len(h.events), m.Snapshot()})
+	}
+}
+
+// at replays the race up to the race time t including the events at t
+func (h *history) at(t time.Time) (monitor.EventMonitor, error) {
+	i := sort.Search(len(h.snapshots), func(i int) bool {
+		n := h.snapshots[i].events
+		return n > 0 && h.events[n-1].Time.After(t)
+	}) - 1
+	m := h.snapshots[i].monitor.Snapshot()
+	for _, event := range h.events[h.snapshots[i].events:] {
+		if event.Time.After(t) {
+			break
+		}
+		if _, err := m.DigestEvent(event); err != nil {
+			return nil, fmt.Errorf("replaying the history: %w", err)
+		}
+	}
+	m.Advance(t)
+	return m, nil
+}
+
+// last returns the time of the last digested event, zero before the first one
+func (h *history) last() time.Time {
+	if len(h.events) == 0 {
+		return time.Time{}
+	}
+	return h.events[len(h.events)-1].Time
+}
+
+// At returns the race as it was at the race time t
+func (race *Race) At(t time.Time) (*Race, error) {
+	m, err := race.history.at(t)
+	if err != nil {
+		return nil, err
+	}
+	return &Race{ID: race.ID, Config: m.Config(), Monitor: m, history: newHistory(m), utc: race.utc}, nil
+}
+
+// At returns the registry with the races as they were at the race time t
+func (r *Registry) At(t time.Time) (*Registry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	past := NewRegistry()
+	past.utc = r.utc
+	for _, id := range r.order {
+		race, err := r.races[id].At(t)
+		if err != nil {
+			return nil, fmt.Errorf("race %q: %w", id, err)
+		}
+		past.races[id] = race
+		past.order = append(past.order, id)
+	}
+	return past, nil
+}
+
+// LastEvent returns the time of the last event digested in any race, zero before the first one
+func (r *Registry) LastEvent() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last time.Time
+	for _, race := range r.races {
+		if t := race.history.last(); !t.IsZero() && (last.IsZero() || t.After(last)) { // time-only times are before the zero time
+			last = t
+		}
+	}
+	return last
+}
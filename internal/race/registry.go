@@ -0,0 +1,252 @@
+package race
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/monitor"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+)
+
+// Race is one competition with its own config and competitors
+type Race struct {
+	ID      string // empty for the only race
+	Config  *config.Config
+	Monitor monitor.EventMonitor
+
+	history history
+	utc     bool // render the times in UTC instead of the venue time
+}
+
+// Registry runs several races at once from one timing system, the events are routed by their race ID.
+// It is safe for concurrent use, unlike the monitors.
+type Registry struct {
+	mu    sync.Mutex
+	races map[string]*Race
+	order []string // in the order of adding
+	utc   bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		races: make(map[string]*Race),
+	}
+}
+
+// Add registers the race, the races may differ in the venue time zone and the timing precision
+func (r *Registry) Add(id string, conf *config.Config) (*Race, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id != "" && !model.IsRaceID(id) {
+		return nil, fmt.Errorf("invalid race ID %q: must be a word starting with a letter or a date", id)
+	}
+	if _, ok := r.races[id]; ok {
+		return nil, fmt.Errorf("race %q is already registered", id)
+	}
+	m := monitor.NewEventMonitor(conf)
+	race := &Race{ID: id, Config: conf, Monitor: m, history: newHistory(m), utc: r.utc}
+	r.races[id] = race
+	r.order = append(r.order, id)
+	return race, nil
+}
+
+// get returns the race by ID, the empty ID stands for the only race
+func (r *Registry) get(id string) *Race {
+	if race, ok := r.races[id]; ok {
+		return race
+	}
+	if id == "" && len(r.order) == 1 {
+		return r.races[r.order[0]]
+	}
+	return nil
+}
+
+// SetDisplayUTC switches the rendered times of all races between their venue time and UTC
+func (r *Registry) SetDisplayUTC(utc bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.utc = utc
+	for _, race := range r.races {
+		race.utc = utc
+	}
+}
+
+// Format returns the time format of the race, of the first race for the unknown ones
+func (r *Registry) Format(id string) model.TimeFormat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	race := r.get(id)
+	if race == nil && len(r.order) > 0 {
+		race = r.races[r.order[0]]
+	}
+	if race == nil {
+		return model.TimeFormat{UTC: r.utc}
+	}
+	return race.Format()
+}
+
+func (r *Registry) has(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.get(id) != nil
+}
+
+// IDs returns the race IDs in the order of adding
+func (r *Registry) IDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.order)
+}
+
+// Do runs f on the race with the registry locked, it reports whether the race exists
+func (r *Registry) Do(id string, f func(race *Race)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	race := r.get(id)
+	if race == nil {
+		return false
+	}
+	f(race)
+	return true
+}
+
+// DigestEvent passes the event to the monitor of its race after advancing all races to its time,
+// the outgoing events are tagged with the race ID. The events scheduled before are returned even
+// if the event itself fails.
+func (r *Registry) DigestEvent(event *model.Event) ([]*model.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	race := r.get(event.Race)
+	if race == nil {
+		if event.Race == "" {
+			return nil, fmt.Errorf("event %d of competitor %d has no race ID", event.EventID, event.CompetitorID)
+		}
+		return nil, fmt.Errorf("unknown race %q", event.Race)
+	}
+	out := r.advance(event.Time)
+	digested, err := race.Monitor.DigestEvent(event)
+	event.Format = race.Format() // after the config change
+	if err == nil {
+		race.history.record(race.Monitor, event)
+	}
+	return append(out, race.tag(digested)...), err
+}
+
+// Advance moves the clocks of all races, the outgoing events are merged in the time order
+func (r *Registry) Advance(now time.Time) []*model.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.advance(now)
+}
+
+func (r *Registry) advance(now time.Time) []*model.Event {
+	var out []*model.Event
+	for _, id := range r.order {
+		race := r.races[id]
+		out = append(out, race.tag(race.Monitor.Advance(now))...)
+	}
+	slices.SortStableFunc(out, func(a, b *model.Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return out
+}
+
+// Disqualified closes the start windows in all races
+func (r *Registry) Disqualified() []*model.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []*model.Event
+	for _, id := range r.order {
+		race := r.races[id]
+		out = append(out, race.tag(race.Monitor.Disqualified())...)
+	}
+	slices.SortStableFunc(out, func(a, b *model.Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return out
+}
+
+// CheckConfig reports the unsafe changes of the race config, see monitor.EventMonitor
+func (r *Registry) CheckConfig(id string, next *config.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	race := r.get(id)
+	if race == nil {
+		return fmt.Errorf("unknown race %q", id)
+	}
+	return race.Monitor.CheckConfig(next)
+}
+
+func (race *Race) tag(events []*model.Event) []*model.Event {
+	for _, e := range events {
+		if e != nil {
+			e.Race = race.ID
+			e.Format = race.Format()
+		}
+	}
+	return events
+}
+
+// Format returns the time format of the race, in its venue time or UTC
+func (race *Race) Format() model.TimeFormat {
+	f := model.FormatOf(race.Config)
+	f.UTC = race.utc
+	return f
+}
+
+// Results builds the report of the race
+func (race *Race) Results() report.RaceResults {
+	res := report.RaceResults{
+		ID:      race.ID,
+		Laps:    race.Config.Laps,
+		Results: report.Results(race.Config, race.Monitor.GetReport()),
+	}
+	status, officialAt := race.Monitor.ResultsStatus()
+	res.Status = status.String()
+	if status == model.Unofficial && !officialAt.IsZero() {
+		res.OfficialAt = race.Format().FormatTime(officialAt)
+	}
+	return res
+}
+
+// Page builds the results page of the races, refresh is the reload period of the live page [s]
+func (r *Registry) Page(ids []string, refresh int) (report.Page, bool) {
+	page := report.Page{Title: "Results", Generated: time.Now().Format(time.DateTime), Refresh: refresh}
+	for _, id := range ids {
+		if !r.Do(id, func(race *Race) { page.Races = append(page.Races, race.Results()) }) {
+			return page, false
+		}
+	}
+	if len(ids) == 1 && ids[0] != "" {
+		page.Title = "Results: " + ids[0]
+	}
+	return page, true
+}
+
+// Sheet builds the results sheet of all races, the event details are taken from the first race config
+func (r *Registry) Sheet() report.Sheet {
+	var sheet report.Sheet
+	sheet.Generated = time.Now().Format(time.DateTime)
+	for i, id := range r.IDs() {
+		r.Do(id, func(race *Race) {
+			if i == 0 {
+				conf := race.Config
+				sheet.Name, sheet.Venue, sheet.Jury = conf.Name, conf.Venue, conf.Jury
+				sheet.Date = time.Now().Format(time.DateOnly)
+				if !model.IsTimeOnly(conf.Start) {
+					sheet.Date = race.Format().RaceID(conf.Start)
+				}
+			}
+			sheet.Races = append(sheet.Races, race.Results())
+		})
+	}
+	return sheet
+}
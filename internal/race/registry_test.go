@@ -0,0 +1,170 @@
+package race_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/stretchr/testify/assert"
+)
+
+func tm(tm string) time.Time {
+	t, err := time.Parse(model.TimeLayout, tm)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func sprint(start string) *config.Config {
+	return &config.Config{
+		Laps:          1,
+		LapLen:        3000,
+		PenaltyLen:    150,
+		FiringLines:   0,
+		Start:         tm(start),
+		StartDelta:    30 * time.Second,
+		ProtestWindow: 15 * time.Minute, // the tests go on after the race is over
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := race.NewRegistry()
+	_, err := reg.Add("men", sprint("10:00:00.000"))
+	assert.NoError(t, err)
+	_, err = reg.Add("women", sprint("10:00:10.000"))
+	assert.NoError(t, err)
+	_, err = reg.Add("men", sprint("10:00:00.000"))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"men", "women"}, reg.IDs())
+
+	var out []string
+	for _, line := range []string{
+		"[09:00:00.000] men 1 1",
+		"[09:00:00.000] women 1 1",
+		"[09:10:00.000] women 2 1 10:00:10.000",
+		"[09:10:00.000] men 2 1 10:00:00.000",
+		"[10:05:00.000] women 3 1",
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		events, err := reg.DigestEvent(event)
+		assert.NoError(t, err)
+		for _, e := range events {
+			out = append(out, e.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"[10:00:30.000] men: The competitor(1) is disqualified",
+		"[10:00:30.000] men: The results are unofficial, the protests are accepted until 10:15:30.000",
+		"[10:00:40.000] women: The competitor(1) is disqualified",
+		"[10:00:40.000] women: The results are unofficial, the protests are accepted until 10:15:40.000",
+	}, out)
+
+	for _, line := range []string{"[10:06:00.000] 3 1", "[10:06:00.000] relay 3 1"} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		_, err = reg.DigestEvent(event)
+		assert.Error(t, err)
+	}
+
+	assert.True(t, reg.Do("women", func(r *race.Race) {
+		assert.Len(t, r.Monitor.GetReport(), 1)
+	}))
+	assert.False(t, reg.Do("", func(*race.Race) {})) // not the only race
+}
+
+func TestRegistryZones(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	assert.NoError(t, err)
+	day := time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)
+	venue := sprint("08:00:00.000") // 10:00 in Oslo in summer
+	venue.Start = model.OnDay(venue.Start, day)
+	venue.TimeZone, venue.Location = "Europe/Oslo", oslo
+	utc := sprint("08:00:10.000")
+	utc.Start = model.OnDay(utc.Start, day)
+	utc.OutputPrecision = config.Hundredths
+
+	reg := race.NewRegistry()
+	_, err = reg.Add("men", venue)
+	assert.NoError(t, err, "the venue time zones and the timing precision may differ")
+	_, err = reg.Add("women", utc)
+	assert.NoError(t, err)
+
+	var out []string
+	for _, line := range []string{
+		"[2025-07-10T07:00:00.000] men 1 1",
+		"[2025-07-10T07:00:00.000] women 1 1",
+		"[2025-07-10T07:10:00.000] men 2 1 2025-07-10T08:00:00.000",
+		"[2025-07-10T07:10:00.000] women 2 1 2025-07-10T08:00:10.000",
+		"[2025-07-10T08:05:00.000] women 3 1",
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		events, err := reg.DigestEvent(event)
+		assert.NoError(t, err)
+		for _, e := range events {
+			out = append(out, e.String())
+		}
+	}
+	assert.Equal(t, []string{
+		"[2025-07-10T10:00:30.000] men: The competitor(1) is disqualified",
+		"[2025-07-10T10:00:30.000] men: The results are unofficial, the protests are accepted until 2025-07-10T10:15:30.000",
+		"[2025-07-10T08:00:40.00] women: The competitor(1) is disqualified",
+		"[2025-07-10T08:00:40.00] women: The results are unofficial, the protests are accepted until 2025-07-10T08:15:40.00",
+	}, out)
+
+	reg.SetDisplayUTC(true)
+	assert.Equal(t, "2025-07-10T08:00:00.000", reg.Format("men").FormatTime(venue.Start))
+}
+
+func TestAt(t *testing.T) {
+	reg := race.NewRegistry()
+	_, err := reg.Add("", sprint("10:00:00.000"))
+	assert.NoError(t, err)
+	var lines []string
+	for id := 1; id <= 40; id++ { // more events than between the snapshots
+		lines = append(lines, fmt.Sprintf("[09:00:00.000] 1 %d", id))
+	}
+	for id := 1; id <= 40; id++ {
+		start := tm("10:00:00.000").Add(time.Duration(id) * 10 * time.Second).Format(model.TimeLayout)
+		lines = append(lines, fmt.Sprintf("[09:10:00.000] 2 %d %s", id, start))
+	}
+	for id := 1; id <= 40; id++ {
+		start := tm("10:00:00.000").Add(time.Duration(id) * 10 * time.Second)
+		lines = append(lines,
+			fmt.Sprintf("[%s] 3 %d", start.Add(-5*time.Second).Format(model.TimeLayout), id),
+			fmt.Sprintf("[%s] 4 %d", start.Format(model.TimeLayout), id))
+	}
+	lines = append(lines, "[10:15:00.000] 10 1", "[10:20:00.000] 10 2")
+	for _, line := range lines {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		_, err = reg.DigestEvent(event)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, tm("10:20:00.000"), reg.LastEvent())
+
+	statuses := func(reg *race.Registry, at string) (res []string) {
+		past, err := reg.At(tm(at))
+		assert.NoError(t, err)
+		past.Do("", func(r *race.Race) {
+			for _, c := range r.Results().Results[:3] {
+				res = append(res, c.Status+" "+c.Where)
+			}
+		})
+		return res
+	}
+	assert.Equal(t, []string{"NotStarted ", "NotStarted ", "NotStarted "}, statuses(reg, "09:30:00.000"))
+	assert.Equal(t, []string{"Running lap 1", "Running lap 1", "NotStarted "}, statuses(reg, "10:00:25.000"))
+	assert.Equal(t, []string{"Finished ", "Running lap 1", "Running lap 1"}, statuses(reg, "10:15:00.000"))
+	assert.Equal(t, []string{"Finished ", "Finished ", "Running lap 1"}, statuses(reg, "10:30:00.000"))
+
+	reg.Do("", func(r *race.Race) { // the live race is untouched
+		assert.Len(t, r.Monitor.GetReport(), 40)
+		assert.Equal(t, model.Finished, r.Monitor.GetReport()[1].Status)
+	})
+}
@@ -0,0 +1,110 @@
+package race
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/monitor"
+)
+
+// Diagnostic is the event which can not be applied to any race, it is reported and skipped
+type Diagnostic struct {
+	Event  *model.Event
+	Reason string
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("skipped event %d of competitor %d at %s: %s",
+		d.Event.EventID, d.Event.CompetitorID, d.Event.Format.FormatTime(d.Event.Time), d.Reason)
+}
+
+// Router dispatches the events without race ID to the race of their day, see model.Event.RaceID,
+// or to the race of the competitor by the start list. The start list is loaded or built from
+// the registrations carrying the race ID.
+type Router struct {
+	*Registry
+	startList map[int]string // competitor ID to race ID
+}
+
+func NewRouter(reg *Registry) *Router {
+	return &Router{
+		Registry:  reg,
+		startList: make(map[int]string),
+	}
+}
+
+// Assign puts the competitor on the start list of the race
+func (rt *Router) Assign(competitorID int, raceID string) error {
+	if !rt.has(raceID) {
+		return fmt.Errorf("unknown race %q", raceID)
+	}
+	if id, ok := rt.startList[competitorID]; ok && id != raceID {
+		return fmt.Errorf("competitor %d is already on the start list of race %q", competitorID, id)
+	}
+	rt.startList[competitorID] = raceID
+	return nil
+}
+
+// LoadStartList reads the start list: each line is the race ID followed by its competitor IDs,
+// the lines starting with # are comments
+func (rt *Router) LoadStartList(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, f := range fields[1:] {
+			id, err := strconv.Atoi(f)
+			if err != nil {
+				return fmt.Errorf("start list line %d: invalid competitor ID %q", n, f)
+			}
+			if err := rt.Assign(id, fields[0]); err != nil {
+				return fmt.Errorf("start list line %d: %w", n, err)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// DigestEvent routes the event to its race, the events which can not be routed
+// or belong to unknown competitors fail with *Diagnostic
+func (rt *Router) DigestEvent(event *model.Event) ([]*model.Event, error) {
+	event.Format = rt.Format(event.Race) // for the diagnostics and the race day, its race sets it again
+	explicit := event.Race != ""
+	if !explicit {
+		day := event.RaceID()
+		id, listed := rt.startList[event.CompetitorID]
+		switch {
+		case rt.has(day):
+			event.Race = day
+		case event.EventID == model.EventConfigChanged: // of the only race
+		case listed:
+			event.Race = id
+		case len(rt.IDs()) > 1:
+			return rt.Advance(event.Time), &Diagnostic{event, "the competitor is not on any start list"}
+		}
+	}
+	if explicit && !rt.has(event.Race) {
+		return rt.Advance(event.Time), &Diagnostic{event, fmt.Sprintf("unknown race %q", event.Race)}
+	}
+	if explicit && event.EventID == model.EventRegister { // the day races share the competitors
+		if err := rt.Assign(event.CompetitorID, event.Race); err != nil {
+			return rt.Advance(event.Time), &Diagnostic{event, err.Error()}
+		}
+	}
+
+	out, err := rt.Registry.DigestEvent(event)
+	if errors.Is(err, monitor.ErrUnknownCompetitor) {
+		return out, &Diagnostic{event, "the competitor is not registered"}
+	}
+	if errors.Is(err, monitor.ErrResultsOfficial) {
+		return out, &Diagnostic{event, "the results are official"}
+	}
+	return out, err
+}
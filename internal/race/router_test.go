@@ -0,0 +1,164 @@
+package race_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	reg := race.NewRegistry()
+	_, err := reg.Add("men", sprint("10:00:00.000"))
+	assert.NoError(t, err)
+	_, err = reg.Add("women", sprint("10:00:00.000"))
+	assert.NoError(t, err)
+
+	router := race.NewRouter(reg)
+	assert.NoError(t, router.LoadStartList(strings.NewReader("# bibs\nmen 1 2\nwomen 3\n")))
+	assert.Error(t, router.LoadStartList(strings.NewReader("women 1\n")))
+	assert.Error(t, router.LoadStartList(strings.NewReader("relay 7\n")))
+
+	var skipped []int
+	for _, line := range []string{
+		"[09:00:00.000] 1 1",
+		"[09:00:00.000] 1 3",
+		"[09:00:00.000] women 1 4", // registered with the race ID
+		"[09:01:00.000] 3 4",
+		"[09:01:00.000] 3 5",       // not on the start lists
+		"[09:01:00.000] 3 2",       // not registered
+		"[09:01:00.000] relay 1 6", // unknown race
+		"[09:01:00.000] women 1 1", // already on the men's start list
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		_, err = router.DigestEvent(event)
+		var diag *race.Diagnostic
+		if errors.As(err, &diag) {
+			skipped = append(skipped, diag.Event.CompetitorID)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, []int{5, 2, 6, 1}, skipped)
+
+	ids := func(raceID string) (ids []int) {
+		reg.Do(raceID, func(r *race.Race) {
+			for _, c := range r.Monitor.GetReport() {
+				ids = append(ids, c.ID)
+			}
+		})
+		return ids
+	}
+	assert.Equal(t, []int{1}, ids("men"))
+	assert.Equal(t, []int{3, 4}, ids("women"))
+}
+
+func TestRaceDays(t *testing.T) {
+	reg := race.NewRegistry()
+	day2 := sprint("10:00:00.000")
+	day2.Start = day2.Start.AddDate(0, 0, 1)
+	_, err := reg.Add("day1", sprint("10:00:00.000"))
+	assert.NoError(t, err)
+	_, err = reg.Add("day2", day2)
+	assert.NoError(t, err)
+
+	router := race.NewRouter(reg)
+	var out []string
+	for _, day := range []int{0, 1} {
+		for _, line := range []string{
+			"[09:00:00.000] 1 1", // registered on each day
+			"[09:10:00.000] 2 1 10:00:00.000",
+			"[09:59:00.000] 3 1",
+			"[10:00:00.000] 4 1",
+			fmt.Sprintf("[10:1%d:00.000] 10 1", day),
+		} {
+			event, err := model.ParseEvent(line)
+			assert.NoError(t, err)
+			event.Time = event.Time.AddDate(0, 0, day) // rolled over midnight by the provider
+			if start, ok := event.ExtraParams.(time.Time); ok {
+				event.ExtraParams = start.AddDate(0, 0, day)
+			}
+			events, err := router.DigestEvent(event)
+			assert.NoError(t, err, line)
+			for _, e := range events {
+				out = append(out, e.String())
+			}
+		}
+	}
+	assert.Equal(t, []string{
+		"[10:10:00.000] day1: The competitor(1) has finished",
+		"[10:10:00.000] day1: The results are unofficial, the protests are accepted until 10:25:00.000",
+		"[10:25:00.000] day1: The results are official",
+		"[10:11:00.000] day2: The competitor(1) has finished",
+		"[10:11:00.000] day2: The results are unofficial, the protests are accepted until 10:26:00.000",
+	}, out)
+
+	for id, total := range map[string]time.Duration{"day1": 10 * time.Minute, "day2": 11 * time.Minute} {
+		reg.Do(id, func(r *race.Race) {
+			report := r.Monitor.GetReport()
+			assert.Len(t, report, 1)
+			assert.Equal(t, total, report[0].TotalTime(), id)
+		})
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	races := func() *race.Router {
+		reg := race.NewRegistry()
+		_, err := reg.Add("men", sprint("10:00:00.000"))
+		assert.NoError(t, err)
+		_, err = reg.Add("women", sprint("10:00:10.000"))
+		assert.NoError(t, err)
+		return race.NewRouter(reg)
+	}
+	router := races()
+	for _, line := range []string{
+		"[09:00:00.000] men 1 1",
+		"[09:00:00.000] women 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:10.000",
+		"[09:59:00.000] 3 1",
+		"[09:59:30.000] 3 2",
+		"[10:00:00.000] 4 1",
+		"[10:00:10.000] 4 2",
+		"[10:10:00.000] 10 1",
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		_, err = router.DigestEvent(event)
+		assert.NoError(t, err)
+	}
+	snapshot := router.Snapshot(9)
+	assert.Equal(t, 9, snapshot.Consumed)
+	assert.Equal(t, "[09:10:00.000] women 2 2 10:00:10.000", snapshot.Events[3])
+
+	resumed := races()
+	assert.NoError(t, resumed.Restore(snapshot))
+	for _, id := range []string{"men", "women"} {
+		var before, after []string
+		router.Do(id, func(r *race.Race) { before = append(before, reportLines(r)...) })
+		resumed.Do(id, func(r *race.Race) { after = append(after, reportLines(r)...) })
+		assert.Equal(t, before, after)
+	}
+	event, err := model.ParseEvent("[10:11:00.000] 10 2") // routed by the restored registrations
+	assert.NoError(t, err)
+	_, err = resumed.DigestEvent(event)
+	assert.NoError(t, err)
+
+	other := race.NewRouter(race.NewRegistry())
+	assert.Error(t, other.Restore(snapshot))
+}
+
+func reportLines(r *race.Race) []string {
+	var lines []string
+	for _, c := range r.Monitor.GetReport() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
@@ -0,0 +1,83 @@
+package race
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/provider"
+	"github.com/GitProger/go-telecom-2025/internal/tz"
+)
+
+// Snapshot is the saved state of the races to resume the interrupted run from
+type Snapshot struct {
+	Saved    string   `json:"saved"`    // wall time of saving
+	Races    []string `json:"races"`    // IDs of the races, the resumed run must have the same
+	Consumed int      `json:"consumed"` // events read from the input, skipped by the resumed run on the same input
+	Events   []string `json:"events"`   // digested events in the journal format: UTC times with the race IDs
+}
+
+// Snapshot saves the digested events of all races, consumed is the number of events read from the input
+func (r *Registry) Snapshot(consumed int) Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*model.Event
+	for _, id := range r.order {
+		events = append(events, r.races[id].history.events...)
+	}
+	slices.SortStableFunc(events, func(a, b *model.Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	s := Snapshot{
+		Saved:    time.Now().Format(time.DateTime),
+		Races:    slices.Clone(r.order),
+		Consumed: consumed,
+		Events:   make([]string, len(events)),
+	}
+	for i, e := range events {
+		s.Events[i] = e.Line()
+	}
+	return s
+}
+
+// Restore digests the events of the snapshot again, the outgoing events are dropped
+func (rt *Router) Restore(s Snapshot) error {
+	if ids := rt.IDs(); !slices.Equal(ids, s.Races) {
+		return fmt.Errorf("the snapshot is of the races %q, not %q", s.Races, ids)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := provider.ScanIn(ctx, strings.NewReader(strings.Join(s.Events, "\n")), tz.Zone{})
+	for event := range events {
+		if _, err := rt.DigestEvent(event); err != nil {
+			return fmt.Errorf("restoring the snapshot: %w", err)
+		}
+	}
+	return <-errs
+}
+
+func WriteSnapshot(path string, s Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func ReadSnapshot(path string) (Snapshot, error) {
+	var s Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,49 @@
+package report
+
+import (
+	"embed"
+	"html/template"
+	"io"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.New("results.html").Funcs(template.FuncMap{
+	"shooting": ShootingString,
+}).ParseFS(templates, "templates/results.html"))
+
+// Page is the results page of one or several races
+type Page struct {
+	Title     string
+	Generated string // when the results were generated
+	Refresh   int    // reload period of the live page [s], 0 for the final results
+	Races     []RaceResults
+}
+
+type RaceResults struct {
+	ID         string // empty for the only race
+	Laps       int
+	Status     string // of the results: Provisional, Unofficial or Official, none if empty
+	OfficialAt string // end of the protest window of the unofficial results
+	Results    []Result
+}
+
+// LapNumbers are the lap columns of the table
+func (r RaceResults) LapNumbers() []int {
+	numbers := make([]int, r.Laps)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	return numbers
+}
+
+// Missing are the empty lap columns of the result
+func (r RaceResults) Missing(res Result) []struct{} {
+	return make([]struct{}, max(r.Laps-len(res.Laps), 0))
+}
+
+// WriteHTML renders the results page
+func WriteHTML(w io.Writer, page Page) error {
+	return htmlTemplate.Execute(w, page)
+}
@@ -0,0 +1,282 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sheet is the printable official results sheet
+type Sheet struct {
+	Name      string // event name
+	Date      string
+	Venue     string
+	Jury      []string // signing the results
+	Generated string   // when the results were generated
+	Races     []RaceResults
+}
+
+// A4 page in points, the standard Helvetica fonts need no embedding
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+	rowHeight  = 14
+	footerY    = 25
+)
+
+type column struct {
+	title string
+	x     float64
+}
+
+var columns = []column{{"Rank", 40}, {"Bib", 80}, {"Name", 115}, {"Shooting", 290}, {"Penalty", 360}, {"Time", 410}, {"Behind", 490}}
+
+// sections of the competitors out of the ranking, by the result status
+var sections = []struct{ status, title string }{
+	{"Pulled", "Lapped"},
+	{"Running", "Running"},
+	{"NotStarted", "Did not start (DNS)"},
+	{"NotFinished", "Did not finish (DNF)"},
+	{"DSQ", "Disqualified (DSQ)"},
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// sheetLayout places the sheet on the pages top down
+type sheetLayout struct {
+	sheet *Sheet
+	pages []*pdfPage
+	page  *pdfPage
+	y     float64
+
+	status string // of the results of the current race
+}
+
+func (l *sheetLayout) newPage() {
+	l.page = &pdfPage{}
+	l.pages = append(l.pages, l.page)
+	l.y = pageHeight - margin
+
+	title := l.sheet.Name
+	if title == "" {
+		title = "Biathlon"
+	}
+	l.text(margin, l.y, true, 16, title)
+	l.y -= 20
+	var details []string
+	for _, s := range []string{l.sheet.Date, l.sheet.Venue} {
+		if s != "" {
+			details = append(details, s)
+		}
+	}
+	l.text(margin, l.y, false, 10, strings.Join(details, ", "))
+	status := "Results"
+	if l.status != "" {
+		status = l.status + " results"
+	}
+	l.text(pageWidth-margin-5.6*float64(len(status)), l.y, true, 10, status)
+	l.y -= 8
+	l.rule(l.y, 1)
+	l.y -= 20
+}
+
+// need starts a new page if the height does not fit, then calls onNewPage
+func (l *sheetLayout) need(height float64, onNewPage func()) {
+	if l.y-height < margin+footerY {
+		l.newPage()
+		if onNewPage != nil {
+			onNewPage()
+		}
+	}
+}
+
+func (l *sheetLayout) text(x, y float64, bold bool, size float64, s string) {
+	if s == "" {
+		return
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&l.page.content, "BT /%s %g Tf %g %g Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+func (l *sheetLayout) rule(y, width float64) {
+	fmt.Fprintf(&l.page.content, "%g w %d %g m %d %g l S\n", width, margin, y, pageWidth-margin, y)
+}
+
+func (l *sheetLayout) heading(s string) {
+	l.need(3*rowHeight, nil)
+	l.text(margin, l.y, true, 12, s)
+	l.y -= rowHeight + 4
+}
+
+func (l *sheetLayout) tableHeader() {
+	for _, c := range columns {
+		l.text(c.x, l.y, true, 9, c.title)
+	}
+	l.rule(l.y-4, 0.5)
+	l.y -= rowHeight + 2
+}
+
+func (l *sheetLayout) row(res Result) {
+	l.need(rowHeight, l.tableHeader)
+	cells := []string{"", fmt.Sprint(res.ID), res.Name, ShootingString(res.Shooting), "", res.Time, res.Behind}
+	if res.Rank != 0 {
+		cells[0] = fmt.Sprintf("%d.", res.Rank)
+	}
+	if res.PenaltyLoops != 0 {
+		cells[4] = fmt.Sprint(res.PenaltyLoops)
+	}
+	if res.Where != "" { // on course at the time of the last completed lap
+		cells[5], cells[6] = res.Elapsed, res.Where
+	}
+	if res.DSQReason != "" {
+		cells[5] = res.DSQReason
+	}
+	for i, c := range columns {
+		l.text(c.x, l.y, false, 9, cells[i])
+	}
+	l.y -= rowHeight
+}
+
+func (l *sheetLayout) race(race RaceResults) {
+	if race.ID != "" {
+		l.heading(race.ID)
+	}
+	for i, res := range race.Results {
+		if res.Rank == 0 {
+			continue
+		}
+		if i == 0 {
+			l.need(2*rowHeight, nil)
+			l.tableHeader()
+		}
+		l.row(res)
+	}
+	for _, s := range sections {
+		var rows []Result
+		for _, res := range race.Results {
+			if res.Rank == 0 && res.Status == s.status {
+				rows = append(rows, res)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		l.y -= rowHeight / 2
+		l.heading(s.title)
+		l.tableHeader()
+		for _, res := range rows {
+			l.row(res)
+		}
+	}
+	l.y -= rowHeight
+}
+
+func (l *sheetLayout) jury() {
+	if len(l.sheet.Jury) == 0 {
+		return
+	}
+	l.heading("Jury")
+	for _, member := range l.sheet.Jury {
+		l.need(2*rowHeight+10, nil)
+		l.y -= rowHeight + 10
+		l.text(margin, l.y, false, 10, member)
+		fmt.Fprintf(&l.page.content, "0.5 w %d %g m %d %g l S\n", 250, l.y-2, 450, l.y-2)
+		l.y -= 6
+	}
+}
+
+// WritePDF renders the sheet as a PDF document, each race starts on a new page
+func WritePDF(w io.Writer, sheet Sheet) error {
+	l := &sheetLayout{sheet: &sheet}
+	for i, race := range sheet.Races {
+		l.status = race.Status
+		if i == 0 || race.ID != "" {
+			l.newPage()
+		}
+		l.race(race)
+	}
+	if len(sheet.Races) == 0 {
+		l.newPage()
+	}
+	l.jury()
+
+	for i, p := range l.pages {
+		l.page = p
+		l.rule(margin+footerY-10, 0.5)
+		l.text(margin, margin, false, 8, "Generated "+sheet.Generated)
+		l.text(pageWidth-margin-50, margin, false, 8, fmt.Sprintf("Page %d of %d", i+1, len(l.pages)))
+	}
+	return writePDF(w, l.pages)
+}
+
+// writePDF writes the document: catalog, page tree, fonts, then each page with its content
+func writePDF(w io.Writer, pages []*pdfPage) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i)
+		object("<< /Length %d >>\nstream\n%s\nendstream", p.content.Len(), p.content.Bytes())
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// winAnsi are the WinAnsiEncoding characters out of Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, 'Š': 0x8a, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '–': 0x96, '—': 0x97, '™': 0x99, 'š': 0x9a, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfString escapes the text for a PDF string literal in WinAnsiEncoding, Latin-1 is kept as is
+func pdfString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < ' ':
+		case r < 0x80:
+			sb.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			sb.WriteByte(byte(r))
+		case winAnsi[r] != 0:
+			sb.WriteByte(winAnsi[r])
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package report_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/GitProger/go-telecom-2025/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePDF(t *testing.T) {
+	race := report.RaceResults{Laps: 2}
+	for i := 1; i <= 80; i++ {
+		race.Results = append(race.Results, report.Result{Rank: i, ID: i, Status: "Finished", Time: "00:25:18.356", Shooting: []int{0, 1}})
+	}
+	race.Results = append(race.Results,
+		report.Result{ID: 81, Name: "Søren Šimek", Status: "NotStarted"},
+		report.Result{ID: 82, Status: "DSQ", DSQReason: "false start"})
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WritePDF(&buf, report.Sheet{
+		Name:      "Sprint (men)",
+		Date:      "2025-03-01",
+		Jury:      []string{"Anna Berg"},
+		Generated: "2025-03-01 12:00:00",
+		Races:     []report.RaceResults{race},
+	}))
+	pdf := buf.Bytes()
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	xref, err := strconv.Atoi(string(regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(pdf)[1]))
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+	for i, m := range regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1) {
+		off, _ := strconv.Atoi(string(m[1]))
+		assert.True(t, bytes.HasPrefix(pdf[off:], fmt.Appendf(nil, "%d 0 obj\n", i+1)), "object %d", i+1)
+	}
+
+	assert.Contains(t, string(pdf), "/Count 2")
+	assert.Contains(t, string(pdf), "(Sprint \\(men\\)) Tj")
+	assert.Contains(t, string(pdf), "(Page 2 of 2) Tj")
+	assert.Contains(t, string(pdf), "(Did not start \\(DNS\\)) Tj")
+	assert.Contains(t, string(pdf), "(S\xf8ren \x8aimek) Tj") // WinAnsiEncoding
+	assert.Contains(t, string(pdf), "(false start) Tj")
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/service"
+)
+
+type Lap struct {
+	Time  string  `json:"time"`
+	Speed float64 `json:"speed"`
+}
+
+// Result is the report line of the competitor
+type Result struct {
+	Rank         int    `json:"rank,omitempty"` // only the finishers are ranked
+	ID           int    `json:"id"`
+	Name         string `json:"name,omitempty"`
+	Status       string `json:"status"`
+	Where        string `json:"where,omitempty"` // of the running competitors: "lap 2", "range 1" or "penalty"
+	DSQReason    string `json:"dsqReason,omitempty"`
+	Time         string `json:"time,omitempty"`   // total time of the finishers
+	Behind       string `json:"behind,omitempty"` // behind the winner
+	Laps         []Lap  `json:"laps"`
+	Shooting     []int  `json:"shooting"` // misses on each firing line
+	PenaltyLaps  *Lap   `json:"penaltyLaps,omitempty"`
+	PenaltyLoops int    `json:"penaltyLoops"`
+	Hits         int    `json:"hits"`
+	Shots        int    `json:"shots"`
+
+	Elapsed       string `json:"elapsed,omitempty"`       // time of the running competitors at the last completed lap
+	Projected     string `json:"projected,omitempty"`     // projected total time of the running competitors
+	PredictedRank int    `json:"predictedRank,omitempty"` // by the projected time among the finishers and the projections
+}
+
+// Results builds the report lines of the competitors in the order of service.CompetitorService.GetAll
+func Results(conf *config.Config, competitors []*model.Competitor) []Result {
+	ranks := service.Ranks(competitors)
+	predicted := service.PredictedRanks(competitors)
+	results := make([]Result, len(competitors))
+	f := model.FormatOf(conf)
+	var winner *model.Competitor
+	for i, c := range competitors {
+		res := Result{
+			Rank:     ranks[i],
+			ID:       c.ID,
+			Name:     c.Name,
+			Status:   c.Status.String(),
+			Laps:     []Lap{},
+			Shooting: append([]int{}, c.Misses...),
+			Hits:     c.PassedHits(),
+			Shots:    c.Shots(),
+		}
+		if c.Status == model.Started && !c.Disqualified {
+			res.Where = where(c)
+			if len(c.Laps) > 0 {
+				res.Elapsed = f.FormatDuration(c.TotalTime())
+			}
+		}
+		if predicted[i] != 0 {
+			res.Projected = f.FormatDuration(c.Projected)
+			res.PredictedRank = predicted[i]
+		}
+		for _, m := range c.Misses {
+			res.PenaltyLoops += m
+		}
+		if c.Disqualified {
+			res.Status = "DSQ"
+			res.DSQReason = c.DSQReason.String()
+		}
+		if ranks[i] != 0 {
+			res.Time = f.FormatDuration(c.TotalTime())
+			if winner == nil {
+				winner = c
+			} else {
+				res.Behind = "+" + f.FormatDuration(c.OfficialTime()-winner.OfficialTime())
+			}
+		}
+		for j, d := range c.Laps {
+			res.Laps = append(res.Laps, Lap{f.FormatDuration(d), model.Speed(conf.LapLength(j), d)})
+		}
+		if c.PenaltyLaps != 0 {
+			res.PenaltyLaps = &Lap{f.FormatDuration(c.PenaltyLaps), model.Speed(c.PenaltyDistance(), c.PenaltyLaps)}
+		}
+		results[i] = res
+	}
+	return results
+}
+
+func where(c *model.Competitor) string {
+	switch {
+	case c.IsFiring:
+		return fmt.Sprintf("range %d", c.FiringLines+1)
+	case !c.PenaltyStartTime.IsZero():
+		return "penalty"
+	default:
+		return fmt.Sprintf("lap %d", len(c.Laps)+1)
+	}
+}
+
+// ShootingString renders the misses in the classic notation, e.g. "0 1 2 0"
+func ShootingString(misses []int) string {
+	s := make([]string, len(misses))
+	for i, m := range misses {
+		s[i] = strconv.Itoa(m)
+	}
+	return strings.Join(s, " ")
+}
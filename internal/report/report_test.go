@@ -0,0 +1,94 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResults(t *testing.T) {
+	conf := &config.Config{
+		Laps:        2,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		StartDelta:  30 * time.Second,
+	}
+	finisher := func(id int, total time.Duration, misses ...int) *model.Competitor {
+		c := model.NewCompetitor(id, conf)
+		c.Status = model.Finished
+		c.PlannedStartTime = conf.Start
+		c.LapStartTime = conf.Start.Add(total)
+		c.Laps = []time.Duration{total / 2, total / 2}
+		c.FiringLines = 2
+		c.Misses = misses
+		c.Hits = 10 - misses[0] - misses[1]
+		return c
+	}
+	dnf := model.NewCompetitor(3, conf)
+	dnf.Status = model.NotFinished
+	dnf.Name = "Ola Nordmann"
+
+	results := report.Results(conf, []*model.Competitor{
+		finisher(2, 20*time.Minute, 0, 1),
+		finisher(1, 20*time.Minute+7500*time.Millisecond, 2, 0),
+		dnf,
+	})
+	assert.Equal(t, []int{1, 2, 0}, []int{results[0].Rank, results[1].Rank, results[2].Rank})
+	assert.Equal(t, "", results[0].Behind)
+	assert.Equal(t, "+00:00:07.500", results[1].Behind)
+	assert.Equal(t, "2 0", report.ShootingString(results[1].Shooting))
+	assert.Equal(t, 2, results[1].PenaltyLoops)
+	assert.Equal(t, "NotFinished", results[2].Status)
+
+	var sb strings.Builder
+	assert.NoError(t, report.WriteHTML(&sb, report.Page{Title: "Sprint", Races: []report.RaceResults{{Laps: 2, Status: "Unofficial", OfficialAt: "10:45:00.000", Results: results}}}))
+	html := sb.String()
+	assert.Contains(t, html, `<p class="status">Unofficial results, protests until 10:45:00.000</p>`)
+	assert.NotContains(t, html, "http-equiv")
+	assert.Contains(t, html, "<th>Lap 2</th>")
+	assert.Contains(t, html, `<td class="shooting">0 1</td>`)
+	assert.Contains(t, html, `<td class="status" colspan="2">NotFinished</td>`)
+	assert.Equal(t, 3, strings.Count(html[strings.Index(html, "Ola Nordmann"):], "<td></td>")) // no laps, no penalty
+}
+
+func TestRunning(t *testing.T) {
+	conf := &config.Config{
+		Laps:        2,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		StartDelta:  30 * time.Second,
+	}
+	c := model.NewCompetitor(4, conf)
+	c.Status = model.Started
+	c.PlannedStartTime = conf.Start
+	c.LapStartTime = conf.Start.Add(10 * time.Minute)
+	c.Laps = []time.Duration{10 * time.Minute}
+	c.FiringLines, c.Misses, c.Hits = 1, []int{1}, 4
+	c.IsFiring, c.RangeStartHits, c.Hits = true, 4, 6
+
+	results := report.Results(conf, []*model.Competitor{c})
+	assert.Equal(t, "range 2", results[0].Where)
+	assert.Equal(t, "00:10:00.000", results[0].Elapsed)
+	assert.Equal(t, "", results[0].Time)
+	assert.Equal(t, 4, results[0].Hits, "the current firing line is not counted yet")
+	assert.Equal(t, 5, results[0].Shots)
+
+	var sb strings.Builder
+	assert.NoError(t, report.WriteText(&sb, report.RaceResults{Laps: 2, Results: results}))
+	assert.Contains(t, sb.String(), "   4                       range 2      1/2   1          00:10:00.000")
+	sb.Reset()
+	assert.NoError(t, report.WriteHTML(&sb, report.Page{Races: []report.RaceResults{{Laps: 2, Results: results}}}))
+	assert.Contains(t, sb.String(), `<td class="status" colspan="2">Running, range 2, 00:10:00.000</td>`)
+	sb.Reset()
+	assert.NoError(t, report.WritePDF(&sb, report.Sheet{Races: []report.RaceResults{{Laps: 2, Results: results}}}))
+	assert.Contains(t, sb.String(), "(range 2) Tj")
+}
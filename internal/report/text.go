@@ -0,0 +1,43 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteText renders the results as a plain text table
+func WriteText(w io.Writer, race RaceResults) error {
+	if err := textLine(w, "#", "Bib", "Name", "Status", "Laps", "Shooting", "Time", "Behind"); err != nil {
+		return err
+	}
+	for _, res := range race.Results {
+		rank, status := "", res.Status
+		if res.Rank != 0 {
+			rank = strconv.Itoa(res.Rank)
+		}
+		if res.Where != "" {
+			status = res.Where
+		}
+		t := res.Time
+		if res.Elapsed != "" {
+			t = res.Elapsed
+		}
+		if res.DSQReason != "" {
+			t = res.DSQReason
+		}
+		err := textLine(w, rank, strconv.Itoa(res.ID), res.Name, status,
+			fmt.Sprintf("%d/%d", len(res.Laps), race.Laps), ShootingString(res.Shooting), t, res.Behind)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func textLine(w io.Writer, rank, id, name, status, laps, shooting, t, behind string) error {
+	line := fmt.Sprintf("%4s %4s  %-20s %-12s %-5s %-10s %-13s %s", rank, id, name, status, laps, shooting, t, behind)
+	_, err := fmt.Fprintln(w, strings.TrimRight(line, " "))
+	return err
+}
@@ -22,6 +22,15 @@ func (cs *CompetitorService) Register(id int, conf *config.Config) {
 	cs.competitors[id] = model.NewCompetitor(id, conf)
 }
 
+// Clone copies the competitors, the copies share the given config
+func (cs *CompetitorService) Clone(conf *config.Config) *CompetitorService {
+	clone := NewCompetitorService()
+	for id, c := range cs.competitors {
+		clone.competitors[id] = c.Clone(conf)
+	}
+	return clone
+}
+
 func (cs *CompetitorService) Delete(id int) {
 	delete(cs.competitors, id)
 }
@@ -43,9 +52,34 @@ func (cs *CompetitorService) GetAll() []*model.Competitor {
 		competitors = append(competitors, c)
 	}
 	sort.Slice(competitors, func(i, j int) bool {
-		t1 := competitors[i].TimeFromPlannedStart()
-		t2 := competitors[j].TimeFromPlannedStart()
+		if g1, g2 := rankGroup(competitors[i]), rankGroup(competitors[j]); g1 != g2 {
+			return g1 < g2
+		}
+		if competitors[i].Status == model.Pulled { // more laps first, then by the time of the last completed lap
+			if l1, l2 := len(competitors[i].Laps), len(competitors[j].Laps); l1 != l2 {
+				return l1 > l2
+			}
+		}
+		t1 := competitors[i].OfficialTime() // equal official times share the rank
+		t2 := competitors[j].OfficialTime()
+		if t1 == t2 {
+			return competitors[i].ID < competitors[j].ID
+		}
 		return t1 < t2
 	})
 	return competitors
 }
+
+// rankGroup orders the report: finishers, pulled, the rest and the disqualified at the bottom
+func rankGroup(c *model.Competitor) int {
+	switch {
+	case c.Disqualified:
+		return 3
+	case c.Status == model.Finished:
+		return 0
+	case c.Status == model.Pulled:
+		return 1
+	default:
+		return 2
+	}
+}
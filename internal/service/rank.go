@@ -0,0 +1,51 @@
+package service
+
+import (
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// Ranks returns the ranks of the competitors ordered by GetAll, the finishers with equal official times
+// share the rank, the others are not ranked (0)
+func Ranks(competitors []*model.Competitor) []int {
+	ranks := make([]int, len(competitors))
+	for i, c := range competitors {
+		if c.Status != model.Finished || c.Disqualified {
+			continue
+		}
+		ranks[i] = i + 1
+		if i > 0 && ranks[i-1] != 0 && competitors[i-1].OfficialTime() == c.OfficialTime() {
+			ranks[i] = ranks[i-1]
+		}
+	}
+	return ranks
+}
+
+// PredictedRanks returns the predicted ranks of the running competitors by their projected times
+// among the finishers and the other projections, 0 for the rest
+func PredictedRanks(competitors []*model.Competitor) []int {
+	var times []time.Duration
+	for _, c := range competitors {
+		switch {
+		case c.Disqualified:
+		case c.Status == model.Finished:
+			times = append(times, c.OfficialTime())
+		case c.Status == model.Started && c.Projected > 0:
+			times = append(times, c.Format().RoundDuration(c.Projected))
+		}
+	}
+	ranks := make([]int, len(competitors))
+	for i, c := range competitors {
+		if c.Status != model.Started || c.Disqualified || c.Projected == 0 {
+			continue
+		}
+		ranks[i] = 1
+		for _, t := range times {
+			if t < c.Format().RoundDuration(c.Projected) {
+				ranks[i]++
+			}
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,154 @@
+package simulate
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+)
+
+// Params are the distributions of the simulated race
+type Params struct {
+	Athletes     int
+	Speed        float64 // mean skiing speed [m/s]
+	SpeedSD      float64 // standard deviation of the speed between the athletes and laps [m/s]
+	PenaltySpeed float64 // share of the skiing speed on the penalty loops
+	HitRate      float64 // probability to hit a target
+	DNF          float64 // probability to not finish
+	LateStart    float64 // probability to come to the start line after the start window
+	Seed         uint64
+}
+
+var DefaultParams = Params{
+	Athletes:     30,
+	Speed:        5.5,
+	SpeedSD:      0.35,
+	PenaltySpeed: 0.7,
+	HitRate:      0.85,
+	DNF:          0.03,
+	LateStart:    0.02,
+	Seed:         1,
+}
+
+var dnfComments = []string{"Lost in the forest", "Broken ski", "Injury", "Exhausted"}
+
+// Generate simulates the race, the events are time ordered in the incoming format,
+// the times are in the venue time zone like a timing system there would send them
+func Generate(conf *config.Config, p Params) []*model.Event {
+	rng := rand.New(rand.NewPCG(p.Seed, p.Seed^0x9e3779b97f4a7c15))
+	start := conf.Zone().FromUTC(conf.Start)
+
+	var events []*model.Event
+	for id := 1; id <= p.Athletes; id++ {
+		sim := athlete{conf: conf, p: &p, rng: rng, id: id}
+		events = append(events, sim.race(start)...)
+	}
+	slices.SortStableFunc(events, func(a, b *model.Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return events
+}
+
+type athlete struct {
+	conf   *config.Config
+	p      *Params
+	rng    *rand.Rand
+	id     int
+	events []*model.Event
+}
+
+// emit records the event at the time t cut to the input precision, as the timing system reads it
+func (a *athlete) emit(t time.Time, eventID int, extra any) {
+	unit := a.conf.InputPrecision.Unit()
+	a.events = append(a.events, &model.Event{
+		EventType:    model.IncomingEvent,
+		EventID:      eventID,
+		CompetitorID: a.id,
+		Time:         t.Add(-time.Duration(t.Nanosecond()) % unit),
+		ExtraParams:  extra,
+		Format:       model.FormatOf(a.conf),
+	})
+}
+
+// seconds returns a uniformly random duration in [lo, hi) seconds
+func (a *athlete) seconds(lo, hi float64) time.Duration {
+	return time.Duration((lo + a.rng.Float64()*(hi-lo)) * float64(time.Second))
+}
+
+func (a *athlete) race(start time.Time) []*model.Event {
+	conf := a.conf
+	planned := start.Add(time.Duration(a.id-1) * conf.StartDelta)
+	a.emit(start.Add(-time.Hour+a.seconds(0, 30*60)), model.EventRegister, nil)
+	a.emit(start.Add(-30*time.Minute+time.Duration(a.id)*time.Second), model.EventStartTimeSet, planned)
+	if a.rng.Float64() < a.p.LateStart { // comes when the start window is closed and is disqualified
+		a.emit(planned.Add(conf.StartDelta+a.seconds(1, 30)), model.EventOnStartLine, nil)
+		return a.events
+	}
+	a.emit(planned.Add(-a.seconds(15, 90)), model.EventOnStartLine, nil)
+
+	now := planned.Add(a.seconds(0, 1.5))
+	a.emit(now, model.EventStarted, nil)
+
+	dnfLap := -1
+	if a.rng.Float64() < a.p.DNF {
+		dnfLap = a.rng.IntN(conf.Laps)
+	}
+	speed := max(a.p.Speed+a.rng.NormFloat64()*a.p.SpeedSD, 1)
+	line := 0
+	for lap := range conf.Laps {
+		lapSpeed := max(speed+a.rng.NormFloat64()*a.p.SpeedSD/3, 1)
+		ski := time.Duration(float64(conf.LapLength(lap)) / lapSpeed * float64(time.Second))
+		if lap == dnfLap {
+			gaveUp := time.Duration((0.2 + 0.7*a.rng.Float64()) * float64(ski))
+			a.emit(now.Add(gaveUp), model.EventCannotContinue, dnfComments[a.rng.IntN(len(dnfComments))])
+			break
+		}
+
+		now = now.Add(ski * 9 / 10)
+		if a.hasFiringLine(lap, line) {
+			line++
+			now = a.shoot(now, line, lapSpeed)
+		}
+		now = now.Add(ski / 10)
+		a.emit(now, model.EventLapCompleted, nil)
+	}
+	return a.events
+}
+
+// hasFiringLine tells whether the lap ends with a firing line, without a course the firing lines
+// are on the first laps
+func (a *athlete) hasFiringLine(lap, passed int) bool {
+	if a.conf.Course != nil {
+		return a.conf.FiringLineOfLap(lap) != 0
+	}
+	return passed < a.conf.FiringLines
+}
+
+// shoot simulates the firing line and the penalty loops, it returns the time the athlete is back on the course
+func (a *athlete) shoot(now time.Time, line int, speed float64) time.Time {
+	a.emit(now, model.EventOnRange, line)
+	now = now.Add(a.seconds(15, 25))
+	misses := 0
+	for target := 1; target <= a.conf.TargetsOnLine(line); target++ {
+		now = now.Add(a.seconds(1.5, 4))
+		if a.rng.Float64() < a.p.HitRate {
+			a.emit(now, model.EventTargetHit, target)
+		} else {
+			misses++
+		}
+	}
+	now = now.Add(a.seconds(3, 8))
+	a.emit(now, model.EventLeftRange, nil)
+	if misses == 0 {
+		return now
+	}
+
+	now = now.Add(a.seconds(5, 12))
+	a.emit(now, model.EventEnteredPenalty, nil)
+	loop := time.Duration(float64(a.conf.PenaltyLen) / (speed * a.p.PenaltySpeed) * float64(time.Second))
+	now = now.Add(time.Duration(misses) * loop)
+	a.emit(now, model.EventLeftPenalty, nil)
+	return now
+}
@@ -0,0 +1,49 @@
+package simulate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/monitor"
+	"github.com/GitProger/go-telecom-2025/internal/simulate"
+	"github.com/stretchr/testify/assert"
+)
+
+func raceConfig() *config.Config {
+	start, _ := time.Parse(model.TimeLayout, "10:00:00.000")
+	return &config.Config{
+		Laps:        3,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 2,
+		Start:       start,
+		StartDelta:  30 * time.Second,
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	p := simulate.DefaultParams
+	p.Athletes = 40
+	events := simulate.Generate(raceConfig(), p)
+	assert.Equal(t, events, simulate.Generate(raceConfig(), p), "the same seed gives the same race")
+
+	m := monitor.NewEventMonitor(raceConfig())
+	finished := 0
+	for i, e := range events {
+		if i > 0 {
+			assert.False(t, e.Time.Before(events[i-1].Time), "the events are time ordered")
+		}
+		out, err := m.DigestEvent(e)
+		assert.NoError(t, err, e.Line())
+		for _, o := range out {
+			if o.EventID == model.EventFinished {
+				finished++
+			}
+		}
+	}
+	m.Disqualified()
+	assert.Len(t, m.GetReport(), p.Athletes)
+	assert.Greater(t, finished, p.Athletes/2)
+}
@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/report"
+)
+
+// ANSI escape sequences, the board needs no terminal library
+const (
+	altScreen  = "\x1b[?1049h"
+	mainScreen = "\x1b[?1049l"
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+	home       = "\x1b[H"
+	clearLine  = "\x1b[K"
+	clearBelow = "\x1b[J"
+
+	bold   = "\x1b[1m"
+	invert = "\x1b[7m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	cyan   = "\x1b[36m"
+	reset  = "\x1b[0m"
+)
+
+const (
+	latestEvents   = 8 // incoming events and messages shown
+	latestOutgoing = 6 // finishes and disqualifications shown
+)
+
+// Board is the full-screen live scoreboard: standings of each race, who is on the range
+// and in the penalty laps, the latest events and the latest finishes and disqualifications
+type Board struct {
+	mu       sync.Mutex
+	w        io.Writer
+	reg      *race.Registry
+	now      time.Time
+	status   string
+	events   []string
+	outgoing []string
+	dirty    bool
+}
+
+// NewBoard switches the terminal to the alternate screen, Close switches it back
+func NewBoard(w io.Writer, reg *race.Registry) *Board {
+	fmt.Fprint(w, altScreen+hideCursor)
+	return &Board{w: w, reg: reg, dirty: true}
+}
+
+func (b *Board) Close() {
+	fmt.Fprint(b.w, reset+showCursor+mainScreen)
+}
+
+// Event records the digested or outgoing event
+func (b *Board) Event(e *model.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.now.IsZero() || e.Time.After(b.now) {
+		b.now = e.Time
+	}
+	line := e.String()
+	switch e.EventID {
+	case model.EventFinished:
+		b.outgoing = keep(append(b.outgoing, green+line+reset), latestOutgoing)
+	case model.EventDisqualified, model.EventPulled:
+		b.outgoing = keep(append(b.outgoing, red+line+reset), latestOutgoing)
+	case model.EventPenaltyWarn, model.EventStartReminder:
+		b.events = keep(append(b.events, yellow+line+reset), latestEvents)
+	default:
+		b.events = keep(append(b.events, line), latestEvents)
+	}
+	b.dirty = true
+}
+
+// Write records the log messages, so the board can be the log output
+func (b *Board) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		b.events = keep(append(b.events, yellow+line+reset), latestEvents)
+	}
+	b.dirty = true
+	return len(p), nil
+}
+
+// SetStatus sets the message of the bottom line
+func (b *Board) SetStatus(status string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.status = status
+	b.dirty = true
+}
+
+// Draw redraws the board if anything has changed
+func (b *Board) Draw() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.dirty {
+		return
+	}
+	b.dirty = false
+
+	width, height := size()
+	var lines []string
+	header := " Biathlon live"
+	if !b.now.IsZero() {
+		header += "  " + b.reg.Format("").FormatTime(b.now)
+	}
+	lines = append(lines, bold+invert+pad(header, width)+reset)
+
+	var onRange, inPenalty []string
+	fixed := 8 + latestEvents + latestOutgoing // headers, range, penalty and status lines
+	ids := b.reg.IDs()
+	rows := (height - fixed) / max(len(ids), 1)
+	for _, id := range ids {
+		b.reg.Do(id, func(r *race.Race) {
+			status, _ := r.Monitor.ResultsStatus()
+			if id != "" {
+				lines = append(lines, bold+cyan+"── "+id+" ── "+status.String()+" results"+reset)
+			} else {
+				lines[0] = bold + invert + pad(header+"  "+status.String()+" results", width) + reset
+			}
+			lines = append(lines, bold+fmt.Sprintf("%4s %4s  %-20s %-12s %-10s %-13s %-14s %s", "#", "Bib", "Name", "Status", "Shooting", "Time", "Behind", "Projected")+reset)
+			comps := r.Monitor.GetReport()
+			for i, res := range report.Results(r.Config, comps) {
+				c := comps[i]
+				label := strings.TrimSpace(id + " " + strconv.Itoa(c.ID) + " " + c.Name)
+				if c.IsFiring {
+					onRange = append(onRange, label)
+				} else if !c.PenaltyStartTime.IsZero() {
+					inPenalty = append(inPenalty, label)
+				}
+				if i < rows-2 {
+					lines = append(lines, standing(res))
+				}
+			}
+		})
+	}
+
+	lines = append(lines, "", yellow+"On range: "+reset+strings.Join(onRange, ", "))
+	lines = append(lines, yellow+"In penalty: "+reset+strings.Join(inPenalty, ", "))
+	lines = append(lines, bold+"── Latest events ──"+reset)
+	lines = append(lines, b.events...)
+	lines = append(lines, bold+"── Finished and disqualified ──"+reset)
+	lines = append(lines, b.outgoing...)
+	lines = append(lines, "", b.status)
+
+	var sb strings.Builder
+	sb.WriteString(home)
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+		sb.WriteString(truncate(line, width))
+		sb.WriteString(reset + clearLine)
+		if i < len(lines)-1 && i < height-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	sb.WriteString(clearBelow)
+	io.WriteString(b.w, sb.String())
+}
+
+// standing is the leaderboard line, the running competitors are shown where they are
+func standing(res report.Result) string {
+	rank := ""
+	if res.Rank != 0 {
+		rank = strconv.Itoa(res.Rank)
+	}
+	status, t := res.Status, res.Time
+	if res.Where != "" {
+		status = res.Where
+	}
+	if res.Elapsed != "" { // at the last completed lap
+		t = res.Elapsed
+	}
+	projected := ""
+	if res.Projected != "" {
+		projected = fmt.Sprintf("~%s (%d)", res.Projected, res.PredictedRank)
+	}
+	line := fmt.Sprintf("%4s %4d  %-20s %-12s %-10s %-13s %-14s %s", rank, res.ID, truncate(res.Name, 20), status,
+		report.ShootingString(res.Shooting), t, res.Behind, projected)
+	if res.DSQReason != "" {
+		return red + line + reset
+	}
+	return line
+}
+
+func keep(lines []string, n int) []string {
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// truncate cuts the line to the width of the terminal, the escape sequences take no width
+func truncate(s string, width int) string {
+	var sb strings.Builder
+	n, escape := 0, false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			escape = true
+		case escape:
+			escape = !(r >= '@' && r <= '~' && r != '[')
+		case n >= width:
+			continue
+		default:
+			n++
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// size returns the terminal size from the environment, 100x40 by default
+func size() (width, height int) {
+	width, height = 100, 40
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		width = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		height = n
+	}
+	return width, height
+}
@@ -0,0 +1,52 @@
+package tui_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/config"
+	"github.com/GitProger/go-telecom-2025/internal/model"
+	"github.com/GitProger/go-telecom-2025/internal/race"
+	"github.com/GitProger/go-telecom-2025/internal/tui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoard(t *testing.T) {
+	start, _ := time.Parse(model.TimeLayout, "10:00:00.000")
+	reg := race.NewRegistry()
+	_, err := reg.Add("", &config.Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: start, StartDelta: 30 * time.Second})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	board := tui.NewBoard(&buf, reg)
+	for _, line := range []string{
+		"[09:00:00.000] 1 1 Ola Nordmann",
+		"[09:00:00.000] 1 2",
+		"[09:10:00.000] 2 1 10:00:00.000",
+		"[09:10:00.000] 2 2 10:00:30.000",
+		"[09:59:00.000] 3 1",
+		"[10:00:00.000] 4 1",
+		"[10:10:00.000] 10 1",
+		"[10:10:10.000] 5 1 1",
+	} {
+		event, err := model.ParseEvent(line)
+		assert.NoError(t, err)
+		out, err := reg.DigestEvent(event)
+		assert.NoError(t, err)
+		board.Event(event)
+		for _, e := range out {
+			board.Event(e)
+		}
+	}
+	board.Draw()
+	board.Close()
+
+	screen := regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]").ReplaceAllString(buf.String(), "")
+	assert.Contains(t, screen, " Biathlon live  10:10:10.000")
+	assert.Regexp(t, `1  Ola Nordmann +range 1 +00:10:00\.000`, screen)
+	assert.Contains(t, screen, "On range: 1 Ola Nordmann")
+	assert.Contains(t, screen, "[10:01:00.000] The competitor(2) is disqualified")
+	assert.Contains(t, screen, "[10:10:10.000] The competitor(1) is on the firing range(1)")
+}
@@ -0,0 +1,54 @@
+package tz
+
+import "time"
+
+// Times are kept internally as UTC. Times given without a date are kept around the year 0
+// and the time zone offset for them is taken on the reference date.
+
+func IsTimeOnly(t time.Time) bool {
+	return t.Year() <= 0 // the time zone shift may move the first race day to the year -1
+}
+
+// Zone converts times between the wall clock of a time zone and UTC
+type Zone struct {
+	Location *time.Location // nil for UTC
+	Ref      time.Time      // date of the time-only times, required for them outside UTC
+}
+
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(name)
+}
+
+func (z Zone) IsUTC() bool {
+	return z.Location == nil || z.Location == time.UTC
+}
+
+func (z Zone) timeOnlyOffset() time.Duration {
+	_, off := z.Ref.In(z.Location).Zone()
+	return time.Duration(off) * time.Second
+}
+
+// ToUTC converts the wall clock reading t of the zone to UTC
+func (z Zone) ToUTC(t time.Time) time.Time {
+	if z.IsUTC() {
+		return t
+	}
+	if IsTimeOnly(t) {
+		return t.Add(-z.timeOnlyOffset())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), z.Location).UTC()
+}
+
+// FromUTC converts t to the wall clock of the zone
+func (z Zone) FromUTC(t time.Time) time.Time {
+	if z.IsUTC() {
+		return t
+	}
+	if IsTimeOnly(t) {
+		return t.Add(z.timeOnlyOffset())
+	}
+	return t.In(z.Location)
+}
@@ -0,0 +1,31 @@
+package tz_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GitProger/go-telecom-2025/internal/tz"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZone(t *testing.T) {
+	oslo, err := tz.Load("Europe/Oslo")
+	assert.NoError(t, err)
+
+	winter := tz.Zone{Location: oslo, Ref: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)}
+	summer := tz.Zone{Location: oslo, Ref: time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)}
+
+	dated := time.Date(2025, 3, 30, 9, 30, 0, 0, time.UTC) // after the DST switch
+	assert.Equal(t, time.Date(2025, 3, 30, 7, 30, 0, 0, time.UTC), winter.ToUTC(dated))
+
+	timeOnly := time.Date(0, 1, 1, 9, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(0, 1, 1, 8, 30, 0, 0, time.UTC), winter.ToUTC(timeOnly))
+	assert.Equal(t, time.Date(0, 1, 1, 7, 30, 0, 0, time.UTC), summer.ToUTC(timeOnly))
+	assert.Equal(t, timeOnly, summer.FromUTC(summer.ToUTC(timeOnly)))
+
+	early := time.Date(0, 1, 1, 0, 30, 0, 0, time.UTC)
+	assert.True(t, tz.IsTimeOnly(winter.ToUTC(early)))
+
+	var utc tz.Zone
+	assert.Equal(t, dated, utc.ToUTC(dated))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/GitProger/go-telecom-2025/internal/config"
 	"github.com/GitProger/go-telecom-2025/internal/model"
 )
 
@@ -17,15 +18,16 @@ func checkType[T any](event *model.Event) error {
 	return nil
 }
 
-func Validate(event *model.Event) error {
+// Validate checks the event against the race config
+func Validate(event *model.Event, conf *config.Config) error {
 	if event.EventType != model.IncomingEvent && event.EventType != model.OutgoingEvent {
 		return fmt.Errorf("unknown event type: %d", event.EventType)
 	}
-	if event.CompetitorID < 1 {
+	if event.CompetitorID < 1 && event.EventID != model.EventConfigChanged {
 		return fmt.Errorf("invalid competitor ID: %d", event.CompetitorID)
 	}
 
-	if (event.EventID < 1 || event.EventID > 11) && (event.EventID != model.EventDisqualified && event.EventID != model.EventFinished) {
+	if (event.EventID < 1 || event.EventID > 16) && (event.EventID < model.EventDisqualified || event.EventID > model.EventPulled) {
 		return fmt.Errorf("unknown event: %d", event.EventID)
 	} else if event.EventID == model.EventOnRange || event.EventID == model.EventTargetHit {
 		if err := checkType[int](event); err != nil {
@@ -34,18 +36,37 @@ func Validate(event *model.Event) error {
 
 		if event.EventID == model.EventTargetHit {
 			target := event.ExtraParams.(int)
-			if target < 1 || target > 5 {
-				return fmt.Errorf("biathlon target number must be from 1 to 5: %d", target)
+			if targets := conf.MaxTargets(); target < 1 || target > targets {
+				return fmt.Errorf("biathlon target number must be from 1 to %d: %d", targets, target)
 			}
 		}
-	} else if event.EventID == model.EventStartTimeSet {
+	} else if event.EventID == model.EventConfigChanged {
+		if err := checkType[*config.Config](event); err != nil {
+			return err
+		}
+	} else if event.EventID == model.EventStartTimeSet || event.EventID == model.EventStartReminder {
 		if err := checkType[time.Time](event); err != nil {
 			return err
 		}
-	} else if event.EventID == model.EventCannotContinue {
+	} else if event.EventID == model.EventCannotContinue || event.EventID == model.EventPenaltyWarn || event.EventID == model.EventPulled {
+		if err := checkType[string](event); err != nil {
+			return err
+		}
+	} else if event.EventID == model.EventTimePenalty || event.EventID == model.EventJuryDisqualified {
+		if err := checkType[model.JuryDecision](event); err != nil {
+			return err
+		}
+		if d := event.ExtraParams.(model.JuryDecision); event.EventID == model.EventTimePenalty && d.Penalty <= 0 {
+			return fmt.Errorf("time penalty must be positive: %v", d.Penalty)
+		}
+	} else if event.EventID == model.EventRegister && event.ExtraParams != nil { // optional name
 		if err := checkType[string](event); err != nil {
 			return err
 		}
+	} else if event.EventID == model.EventDisqualified && event.ExtraParams != nil {
+		if err := checkType[model.Reason](event); err != nil {
+			return err
+		}
 	} else if event.ExtraParams != nil {
 		return fmt.Errorf("unexpected extra params for event %d", event.EventID)
 	}